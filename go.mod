@@ -7,7 +7,9 @@ module periph.io/x/cmd
 go 1.23.0
 
 require (
+	golang.org/x/crypto v0.36.0
 	golang.org/x/image v0.25.0
+	gopkg.in/yaml.v3 v3.0.1
 	periph.io/x/conn/v3 v3.7.2
 	periph.io/x/d2xx v0.1.1
 	periph.io/x/devices/v3 v3.7.4
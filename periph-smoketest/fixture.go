@@ -0,0 +1,209 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+	"periph.io/x/cmd/periph-smoketest/simulate"
+)
+
+// fixture describes how a board is wired to the test hardware, so the smoke
+// tests' arguments can be derived from it.
+//
+// A nil section means the corresponding hardware is not connected.
+type fixture struct {
+	// Board is a free form description, e.g. "Raspberry Pi 4 with
+	// periph-tester".
+	Board string
+	// GPIO describes two pins connected together, for "gpio".
+	GPIO *fixtureGPIO
+	// I2CTestBoard describes the periph-tester I²C EEPROM, for
+	// "i2c-testboard".
+	I2CTestBoard *fixtureI2C
+	// SPITestBoard describes the periph-tester SPI EEPROM, for
+	// "spi-testboard".
+	SPITestBoard *fixtureSPI
+	// OneWireTestBoard describes the periph-tester DS2483, for
+	// "onewire-testboard".
+	OneWireTestBoard *fixtureOneWire
+	// Args are the arguments of the other smoke tests, keyed by name, e.g.
+	// {"bcm283x": ["-quick"]}. For the smoke tests described by a section
+	// above, they are appended to the derived arguments, e.g.
+	// {"gpio": ["-sysfs"]}.
+	Args map[string][]string
+}
+
+type fixtureGPIO struct {
+	Pin1 string
+	Pin2 string
+}
+
+type fixtureI2C struct {
+	// Bus is the I²C bus; the default bus is used when empty.
+	Bus string
+	// WC is the gpio pin connected to the EEPROM write-control pin, if any.
+	WC string
+}
+
+type fixtureSPI struct {
+	// Port is the SPI port; the default port is used when empty.
+	Port string
+	// WP is the gpio pin connected to the EEPROM write-protect pin, if any.
+	WP string
+}
+
+type fixtureOneWire struct {
+	// I2CBus is the I²C bus hosting the DS2483; the default bus is used when
+	// empty.
+	I2CBus string
+}
+
+// simulatedFixture describes the fakes registered by simulate.Register.
+func simulatedFixture() *fixture {
+	return &fixture{
+		Board:            "simulated periph-tester",
+		GPIO:             &fixtureGPIO{Pin1: simulate.GPIO1, Pin2: simulate.GPIO2},
+		I2CTestBoard:     &fixtureI2C{Bus: simulate.I2CBus, WC: simulate.I2CWC},
+		SPITestBoard:     &fixtureSPI{Port: simulate.SPIPort, WP: simulate.SPIWP},
+		OneWireTestBoard: &fixtureOneWire{I2CBus: simulate.I2CBus},
+	}
+}
+
+// loadFixture reads and validates a YAML or JSON fixture file.
+//
+// Unknown fields are rejected, so a typo doesn't silently skip a test.
+func loadFixture(path string) (*fixture, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &fixture{}
+	if err = decodeFixture(raw, f); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if err = f.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return f, nil
+}
+
+// decodeFixture parses raw as YAML, which JSON is a subset of, then decodes it
+// into f with encoding/json. The fields have the same names in both formats.
+//
+// All the values of a fixture are strings, so the scalars that YAML resolves
+// to another type, like the bus in "Bus: 1", are turned back into strings.
+func decodeFixture(raw []byte, f *fixture) error {
+	var doc interface{}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+	if doc == nil {
+		return errors.New("empty fixture")
+	}
+	j, err := json.Marshal(stringify(doc))
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(j))
+	d.DisallowUnknownFields()
+	return d.Decode(f)
+}
+
+// stringify replaces the scalars of the YAML document v that are not strings
+// with their text.
+func stringify(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = stringify(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = stringify(e)
+		}
+	case nil, string:
+	default:
+		return fmt.Sprint(t)
+	}
+	return v
+}
+
+func (f *fixture) validate() error {
+	if f.GPIO != nil && (f.GPIO.Pin1 == "" || f.GPIO.Pin2 == "") {
+		return errors.New("GPIO requires Pin1 and Pin2")
+	}
+	for name := range f.Args {
+		if findTest(name) == nil {
+			return fmt.Errorf("Args: test case %q was not found", name)
+		}
+	}
+	return nil
+}
+
+// args returns the arguments of the smoke test name, and whether the fixture
+// describes it.
+func (f *fixture) args(name string) ([]string, bool) {
+	a, ok := f.derived(name)
+	extra, ok2 := f.Args[name]
+	return append(a, extra...), ok || ok2
+}
+
+// derived returns the arguments derived from the sections describing the
+// wiring.
+func (f *fixture) derived(name string) ([]string, bool) {
+	var out []string
+	add := func(flag, value string) {
+		if value != "" {
+			out = append(out, flag, value)
+		}
+	}
+	switch name {
+	case "gpio":
+		if f.GPIO == nil {
+			return nil, false
+		}
+		add("-pin1", f.GPIO.Pin1)
+		add("-pin2", f.GPIO.Pin2)
+	case "i2c-testboard":
+		if f.I2CTestBoard == nil {
+			return nil, false
+		}
+		add("-bus", f.I2CTestBoard.Bus)
+		add("-wc", f.I2CTestBoard.WC)
+	case "spi-testboard":
+		if f.SPITestBoard == nil {
+			return nil, false
+		}
+		add("-spi", f.SPITestBoard.Port)
+		add("-wp", f.SPITestBoard.WP)
+	case "onewire-testboard":
+		if f.OneWireTestBoard == nil {
+			return nil, false
+		}
+		add("-i2cbus", f.OneWireTestBoard.I2CBus)
+	default:
+		return nil, false
+	}
+	return out, true
+}
+
+// apply prepends the arguments from the fixture to the ones of each smoke
+// test it describes, so the ones on the command line take precedence.
+func (f *fixture) apply(sel []selection) {
+	for i := range sel {
+		a, ok := f.args(sel[i].t.Name())
+		if !ok {
+			continue
+		}
+		sel[i].args = append(append([]string{}, a...), sel[i].args...)
+		sel[i].explicit = true
+	}
+}
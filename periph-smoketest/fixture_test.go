@@ -0,0 +1,160 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// rpi4 is the fixture in the README.
+const rpi4 = `Board: Raspberry Pi 4 with periph-tester
+GPIO: {Pin1: GPIO5, Pin2: GPIO6}
+I2CTestBoard: {Bus: 1, WC: GPIO17}
+SPITestBoard: {Port: SPI0.0, WP: GPIO18}
+OneWireTestBoard: {I2CBus: 1}
+Args:
+  bcm283x: [-quick]
+`
+
+func writeFixture(t *testing.T, name, content string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestLoadFixture(t *testing.T) {
+	want := &fixture{
+		Board:            "Raspberry Pi 4 with periph-tester",
+		GPIO:             &fixtureGPIO{Pin1: "GPIO5", Pin2: "GPIO6"},
+		I2CTestBoard:     &fixtureI2C{Bus: "1", WC: "GPIO17"},
+		SPITestBoard:     &fixtureSPI{Port: "SPI0.0", WP: "GPIO18"},
+		OneWireTestBoard: &fixtureOneWire{I2CBus: "1"},
+		Args:             map[string][]string{"bcm283x": {"-quick"}},
+	}
+	for name, content := range map[string]string{
+		"rpi4.yaml": rpi4,
+		"rpi4.yml":  rpi4,
+		"rpi4.json": `{
+  "Board": "Raspberry Pi 4 with periph-tester",
+  "GPIO": {"Pin1": "GPIO5", "Pin2": "GPIO6"},
+  "I2CTestBoard": {"Bus": "1", "WC": "GPIO17"},
+  "SPITestBoard": {"Port": "SPI0.0", "WP": "GPIO18"},
+  "OneWireTestBoard": {"I2CBus": "1"},
+  "Args": {"bcm283x": ["-quick"]}
+}`,
+	} {
+		f, err := loadFixture(writeFixture(t, name, content))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(f, want) {
+			t.Fatalf("%s: %+v", name, f)
+		}
+	}
+	// The numbers, including in Args, are strings.
+	f, err := loadFixture(writeFixture(t, "f.yaml", "Args:\n  gpio: [-count, 3]\n"))
+	if err != nil || !reflect.DeepEqual(f.Args["gpio"], []string{"-count", "3"}) {
+		t.Fatal(f, err)
+	}
+	if _, err = loadFixture(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestLoadFixtureInvalid(t *testing.T) {
+	for _, c := range []struct {
+		content string
+		err     string
+	}{
+		{"", "empty fixture"},
+		{"Board: [", "yaml"},
+		{"Bord: typo\n", "unknown field"},
+		{"GPIO: {Pin1: GPIO5, Pn2: GPIO6}\n", "unknown field"},
+		{"GPIO: {Pin1: GPIO5}\n", "GPIO requires Pin1 and Pin2"},
+		{"Args: {nope: []}\n", `test case "nope" was not found`},
+		{`{"GPIO": "GPIO5"}`, "cannot unmarshal"},
+	} {
+		_, err := loadFixture(writeFixture(t, "f.yaml", c.content))
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%q: %v", c.content, err)
+		}
+	}
+}
+
+func TestFixtureArgs(t *testing.T) {
+	f := &fixture{
+		GPIO:         &fixtureGPIO{Pin1: "GPIO5", Pin2: "GPIO6"},
+		I2CTestBoard: &fixtureI2C{},
+		SPITestBoard: &fixtureSPI{Port: "SPI0.0"},
+		Args:         map[string][]string{"gpio": {"-sysfs"}, "bcm283x": {"-quick"}},
+	}
+	for _, c := range []struct {
+		name    string
+		derived []string
+		args    []string
+		ok      bool
+	}{
+		{"gpio", []string{"-pin1", "GPIO5", "-pin2", "GPIO6"}, []string{"-pin1", "GPIO5", "-pin2", "GPIO6", "-sysfs"}, true},
+		// The default bus is used.
+		{"i2c-testboard", nil, nil, true},
+		{"spi-testboard", []string{"-spi", "SPI0.0"}, []string{"-spi", "SPI0.0"}, true},
+		// Not connected.
+		{"onewire-testboard", nil, nil, false},
+		// Only in Args.
+		{"bcm283x", nil, []string{"-quick"}, true},
+		{"allwinner", nil, nil, false},
+	} {
+		d, ok := f.derived(c.name)
+		if !reflect.DeepEqual(d, c.derived) || ok != (c.ok && c.name != "bcm283x") {
+			t.Errorf("%s: derived %q %t", c.name, d, ok)
+		}
+		a, ok := f.args(c.name)
+		if !reflect.DeepEqual(a, c.args) || ok != c.ok {
+			t.Errorf("%s: args %q %t", c.name, a, ok)
+		}
+	}
+}
+
+func TestFixtureApply(t *testing.T) {
+	f := &fixture{
+		GPIO: &fixtureGPIO{Pin1: "GPIO5", Pin2: "GPIO6"},
+		Args: map[string][]string{"gpio": {"-sysfs"}},
+	}
+	// The arguments on the command line come last so they take precedence.
+	sel, err := parseSelection([]string{"gpio", "-pin1", "GPIO13", "--", "i2c-testboard"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.apply(sel)
+	if want := []string{"-pin1", "GPIO5", "-pin2", "GPIO6", "-sysfs", "-pin1", "GPIO13"}; !reflect.DeepEqual(sel[0].args, want) || !sel[0].explicit {
+		t.Fatalf("%q", sel[0].args)
+	}
+	if len(sel[1].args) != 0 || !sel[1].explicit {
+		t.Fatalf("%q", sel[1].args)
+	}
+
+	// With all the tests, only the ones described by the fixture get
+	// arguments and run.
+	if sel, err = parseSelection(nil); err != nil {
+		t.Fatal(err)
+	}
+	f.apply(sel)
+	for _, s := range sel {
+		if s.t.Name() == "gpio" {
+			if !s.explicit || len(s.args) != 5 {
+				t.Fatalf("%q", s.args)
+			}
+		} else if s.explicit || len(s.args) != 0 {
+			t.Fatalf("%s: %q", s.t.Name(), s.args)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"flag"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	"periph.io/x/conn/v3/gpio"
@@ -49,6 +50,9 @@ type SmokeTest struct {
 	// expected, we want to make sure it's not flaky.
 	expectedEdgeWait   time.Duration
 	unexpectedEdgeWait time.Duration
+
+	// edges measures the edge detection latency.
+	edges *edgeLatency
 }
 
 // Name implements periph-smoketest.SmokeTest.
@@ -61,12 +65,23 @@ func (s *SmokeTest) Description() string {
 	return "Tests basic functionality, edge detection and input pull resistors"
 }
 
+// Latencies returns the edge detection latencies measured by the last Run,
+// for periph-smoketest soak mode.
+func (s *SmokeTest) Latencies() map[string][]time.Duration {
+	if s.edges == nil {
+		return nil
+	}
+	return map[string][]time.Duration{"edge": s.edges.get()}
+}
+
 // Run implements periph-smoketest.SmokeTest.
 func (s *SmokeTest) Run(f *flag.FlagSet, args []string) error {
+	s.edges = nil
 	pin1 := f.String("pin1", "", "first pin to use")
 	pin2 := f.String("pin2", "", "second pin to use")
 	slow := f.Bool("s", false, "slow; insert a second between each step")
 	useSysfs := f.Bool("sysfs", false, "force the use of sysfs")
+	vcd := f.String("vcd", "", "write a Value Change Dump trace of the pins to this file")
 	if err := f.Parse(args); err != nil {
 		return err
 	}
@@ -122,8 +137,10 @@ func (s *SmokeTest) Run(f *flag.FlagSet, args []string) error {
 	printPin(p1)
 	printPin(p2)
 	s.start = time.Now()
-	pl1 := &loggingPin{p1, s.start}
-	pl2 := &loggingPin{p2, s.start}
+	s.edges = &edgeLatency{}
+	tr := newTracer(s.start, p1.String(), p2.String())
+	pl1 := &loggingPin{p1, s.start, s.edges, tr}
+	pl2 := &loggingPin{p2, s.start, s.edges, tr}
 	if err = s.testCycle(pl1, pl2); err == nil {
 		err = s.testCycle(pl2, pl1)
 	}
@@ -134,6 +151,14 @@ func (s *SmokeTest) Run(f *flag.FlagSet, args []string) error {
 	if err2 := pl2.In(gpio.PullNoChange, gpio.NoEdge); err2 != nil {
 		fmt.Printf("(Exit) Failed to reset %s as input: %s\n", pl1, err2)
 	}
+	if *vcd != "" {
+		if err2 := tr.writeVCDFile(*vcd); err2 != nil {
+			if err == nil {
+				return err2
+			}
+			fmt.Printf("(Exit) Failed to write %s: %s\n", *vcd, err2)
+		}
+	}
 	return err
 }
 
@@ -156,12 +181,20 @@ func (s *SmokeTest) slowSleep() {
 //
 // It waits for a long delay, as the edge trigger should be normally quick, yet
 // we don't want this test to be flaky.
+//
+// When the other pin is set after this call, the edge detection latency is
+// measured.
 func (s *SmokeTest) expectEdge(p gpio.PinIO) <-chan bool {
 	c := make(chan bool)
+	start := time.Now()
 	go func() {
 		// Author note: the function intentionally doesn't call p.Read() to test
 		// that reading is not necessary.
-		c <- p.WaitForEdge(s.expectedEdgeWait)
+		b := p.WaitForEdge(s.expectedEdgeWait)
+		if b {
+			s.edges.detected(start)
+		}
+		c <- b
 	}()
 	return c
 }
@@ -328,18 +361,23 @@ func (s *SmokeTest) testWaitForEdge(p1, p2 gpio.PinIO) (err error) {
 
 	// Halt() unblocks a WaitForEdge()
 	now := time.Now()
+	// The error is passed through a channel since the timer runs
+	// concurrently.
+	halted := make(chan error, 1)
 	t := time.AfterFunc(short, func() {
-		if err2 := p1.Halt(); err == nil {
-			err = err2
-		}
+		halted <- p1.Halt()
 	})
 	if p1.WaitForEdge(timeout) {
 		t.Stop()
 		return fmt.Errorf("unexpected edge; waited for %s", time.Since(now))
 	}
 	if d := time.Since(now); d < short {
+		t.Stop()
 		return fmt.Errorf("wait returned too early after %s; < %s", d, short)
 	} else if d >= timeout {
+		if err = <-halted; err != nil {
+			return err
+		}
 		//return fmt.Errorf("wait timed out after %s; >= %s", d, timeout)
 		fmt.Println("Known failure due to https://github.com/google/periph/issues/323")
 		return nil
@@ -594,30 +632,79 @@ func since(start time.Time) string {
 	return fmt.Sprintf("%3d.%03dms", ms, µs)
 }
 
-// loggingPin logs when its state changes.
+// edgeLatency measures the delay between a pin being set and the other pin
+// detecting the resulting edge.
+//
+// Only the edges caused by setting the pin after starting to wait are
+// measured; the ones accumulated before are not.
+type edgeLatency struct {
+	mu      sync.Mutex
+	lastOut time.Time
+	samples []time.Duration
+}
+
+func (e *edgeLatency) out() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastOut = time.Now()
+}
+
+// detected records the latency of an edge detected by a wait started at
+// waitStart.
+func (e *edgeLatency) detected(waitStart time.Time) {
+	now := time.Now()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.lastOut.After(waitStart) {
+		e.samples = append(e.samples, now.Sub(e.lastOut))
+	}
+}
+
+func (e *edgeLatency) get() []time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]time.Duration(nil), e.samples...)
+}
+
+// loggingPin logs when its state changes and traces all the operations.
 type loggingPin struct {
 	gpio.PinIO
 	start time.Time
+	edges *edgeLatency
+	tr    *tracer
 }
 
 func (p *loggingPin) Halt() error {
 	fmt.Printf("    %s %s.Halt()\n", since(p.start), p)
+	p.tr.record(traceEvent{pin: p.String(), op: opHalt})
 	return p.PinIO.Halt()
 }
 
 func (p *loggingPin) In(pull gpio.Pull, edge gpio.Edge) error {
 	fmt.Printf("    %s %s.In(%s, %s)\n", since(p.start), p, pull, edge)
+	p.tr.record(traceEvent{pin: p.String(), op: opIn, pull: pull, edge: edge})
 	return p.PinIO.In(pull, edge)
 }
 
+// Read is traced but not logged, as it doesn't change the state.
+func (p *loggingPin) Read() gpio.Level {
+	l := p.PinIO.Read()
+	p.tr.record(traceEvent{pin: p.String(), op: opRead, level: l})
+	return l
+}
+
 func (p *loggingPin) WaitForEdge(d time.Duration) bool {
 	fmt.Printf("    %s -> %s.WaitForEdge(%s) ...\n", since(p.start), p, d)
+	p.tr.record(traceEvent{pin: p.String(), op: opWait})
 	b := p.PinIO.WaitForEdge(d)
+	p.tr.record(traceEvent{pin: p.String(), op: opWaitDone, detected: b})
 	fmt.Printf("    %s -> %s.WaitForEdge(%s) -> %t\n", since(p.start), p, d, b)
 	return b
 }
 
 func (p *loggingPin) Out(l gpio.Level) error {
 	fmt.Printf("    %s %s.Out(%s)\n", since(p.start), p, l)
+	p.edges.out()
+	p.tr.record(traceEvent{pin: p.String(), op: opOut, level: l})
 	return p.PinIO.Out(l)
 }
@@ -0,0 +1,174 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package gpiosmoketest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+)
+
+// traceOp is an operation on a pin.
+type traceOp int
+
+const (
+	opHalt traceOp = iota
+	opIn
+	opOut
+	opRead
+	opWait
+	opWaitDone
+)
+
+// traceEvent is an operation recorded by tracer.
+type traceEvent struct {
+	// t is the monotonic time since the start of the test.
+	t   time.Duration
+	pin string
+	op  traceOp
+	// level is the level set by opOut or read by opRead.
+	level gpio.Level
+	// pull and edge are the arguments of opIn.
+	pull gpio.Pull
+	edge gpio.Edge
+	// detected is true when opWaitDone detected an edge.
+	detected bool
+}
+
+// tracer records the operations on the pins, to be exported as a Value
+// Change Dump.
+type tracer struct {
+	start time.Time
+
+	mu     sync.Mutex
+	pins   []string
+	events []traceEvent
+}
+
+func newTracer(start time.Time, pins ...string) *tracer {
+	return &tracer{start: start, pins: pins}
+}
+
+// record records e, setting its timestamp.
+func (t *tracer) record(e traceEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// Take the time with the lock held, so the events are sorted.
+	e.t = time.Since(t.start)
+	t.events = append(t.events, e)
+}
+
+// writeVCDFile writes the trace as a Value Change Dump file.
+func (t *tracer) writeVCDFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = t.writeVCD(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeVCD writes the trace in the Value Change Dump format described in IEEE
+// 1364, which GTKWave and PulseView can display.
+//
+// Each pin has 4 signals:
+//   - drive: the level output by the pin, z when it is an input.
+//   - read: the level returned by the last Read().
+//   - wait: high while in WaitForEdge().
+//   - edge: whether the last WaitForEdge() detected an edge.
+func (t *tracer) writeVCD(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "$date %s $end\n", t.start.Format(time.RFC1123))
+	fmt.Fprintf(b, "$version periph-smoketest gpio $end\n")
+	fmt.Fprintf(b, "$timescale 1ns $end\n")
+	fmt.Fprintf(b, "$scope module gpio $end\n")
+	signals := [...]string{"drive", "read", "wait", "edge"}
+	// ids are the VCD identifiers of the signals of each pin.
+	ids := map[string][len(signals)]string{}
+	n := 0
+	for _, p := range t.pins {
+		fmt.Fprintf(b, "$scope module %s $end\n", vcdName(p))
+		var id [len(signals)]string
+		for i, s := range signals {
+			id[i] = vcdID(n)
+			n++
+			fmt.Fprintf(b, "$var wire 1 %s %s $end\n", id[i], s)
+		}
+		ids[p] = id
+		fmt.Fprintf(b, "$upscope $end\n")
+	}
+	fmt.Fprintf(b, "$upscope $end\n$enddefinitions $end\n")
+	fmt.Fprintf(b, "#0\n$dumpvars\n")
+	for _, p := range t.pins {
+		id := ids[p]
+		fmt.Fprintf(b, "x%s\nx%s\n0%s\n0%s\n", id[0], id[1], id[2], id[3])
+	}
+	fmt.Fprintf(b, "$end\n")
+	last := time.Duration(-1)
+	for _, e := range t.events {
+		id, ok := ids[e.pin]
+		if !ok {
+			continue
+		}
+		if e.t != last {
+			fmt.Fprintf(b, "#%d\n", e.t.Nanoseconds())
+			last = e.t
+		}
+		switch e.op {
+		case opHalt:
+			fmt.Fprintf(b, "$comment %s.Halt() $end\n", e.pin)
+		case opIn:
+			fmt.Fprintf(b, "$comment %s.In(%s, %s) $end\nz%s\n", e.pin, e.pull, e.edge, id[0])
+		case opOut:
+			fmt.Fprintf(b, "%s%s\n", vcdLevel(e.level), id[0])
+		case opRead:
+			fmt.Fprintf(b, "%s%s\n", vcdLevel(e.level), id[1])
+		case opWait:
+			fmt.Fprintf(b, "1%s\n0%s\n", id[2], id[3])
+		case opWaitDone:
+			fmt.Fprintf(b, "0%s\n%s%s\n", id[2], vcdLevel(gpio.Level(e.detected)), id[3])
+		}
+	}
+	return b.Flush()
+}
+
+// vcdID returns the n-th VCD identifier, made of printable ASCII characters
+// except '$', which some parsers confuse with a keyword.
+func vcdID(n int) string {
+	const chars = "!\"#%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~"
+	id := chars[n%len(chars) : n%len(chars)+1]
+	for n /= len(chars); n != 0; n /= len(chars) {
+		id += chars[n%len(chars) : n%len(chars)+1]
+	}
+	return id
+}
+
+// vcdName replaces the characters not allowed in a VCD identifier.
+func vcdName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '$' {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+func vcdLevel(l gpio.Level) string {
+	if l {
+		return "1"
+	}
+	return "0"
+}
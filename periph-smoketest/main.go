@@ -14,10 +14,12 @@ import (
 	"log"
 	"os"
 	"sort"
+	"time"
 
 	"periph.io/x/cmd/periph-smoketest/gpiosmoketest"
 	"periph.io/x/cmd/periph-smoketest/i2csmoketest"
 	"periph.io/x/cmd/periph-smoketest/onewiresmoketest"
+	"periph.io/x/cmd/periph-smoketest/simulate"
 	"periph.io/x/cmd/periph-smoketest/spismoketest"
 	"periph.io/x/devices/v3/bmxx80/bmx280smoketest"
 	"periph.io/x/devices/v3/ssd1306/ssd1306smoketest"
@@ -63,7 +65,8 @@ var tests = []SmokeTest{
 }
 
 func usage(fs *flag.FlagSet) {
-	_, _ = io.WriteString(os.Stderr, "Usage: periph-smoketest <args> <name> ...\n\n")
+	_, _ = io.WriteString(os.Stderr, "Usage: periph-smoketest <args> <name> ...\n")
+	_, _ = io.WriteString(os.Stderr, "       periph-smoketest <args> all [<name> ... [-- <name> ...]]\n\n")
 	fs.PrintDefaults()
 	_, _ = io.WriteString(os.Stderr, "\nTests available:\n")
 	names := make([]string, len(tests))
@@ -90,6 +93,12 @@ func mainImpl() error {
 	}
 	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 	verbose := fs.Bool("v", false, "verbose mode")
+	jsonPath := fs.String("json", "", "with all or soak mode, write a JSON report to this file")
+	junitPath := fs.String("junit", "", "with all or soak mode, write a JUnit XML report to this file")
+	count := fs.Int("count", 0, "soak mode; repeat the test this many times")
+	duration := fs.Duration("duration", 0, "soak mode; repeat the test for this long, e.g. 8h")
+	fixturePath := fs.String("fixture", "", "YAML or JSON file describing the wiring, to derive the tests' arguments from")
+	simulated := fs.Bool("simulate", false, "run against in-process fakes of the periph-tester board")
 	fs.Usage = func() { usage(fs) }
 	if err = fs.Parse(os.Args[1:]); err == flag.ErrHelp {
 		return nil
@@ -131,26 +140,139 @@ func mainImpl() error {
 		}
 	}
 
-	for _, t := range tests {
-		if t.Name() == cmd {
-			f := flag.NewFlagSet("periph-smoketest "+t.Name(), flag.ExitOnError)
-			u := f.Usage
-			f.Usage = func() {
-				fmt.Printf("%s: %s\n\n", t.Name(), t.Description())
-				u()
-				flags := false
-				f.VisitAll(func(*flag.Flag) { flags = true })
-				if !flags {
-					fmt.Printf("  This smoke test doesn't have any flag.\n")
-				}
-			}
-			if err = t.Run(f, fs.Args()[1:]); err == nil {
-				log.Printf("Test %s successful", cmd)
-			}
+	var fx *fixture
+	if *simulated {
+		if *fixturePath != "" {
+			return errors.New("-simulate and -fixture are mutually exclusive")
+		}
+		if err = simulate.Register(); err != nil {
+			return fmt.Errorf("error registering the fakes: %v", err)
+		}
+		fx = simulatedFixture()
+	} else if *fixturePath != "" {
+		if fx, err = loadFixture(*fixturePath); err != nil {
+			return err
+		}
+		log.Printf("Using fixture %s: %s", *fixturePath, fx.Board)
+	}
+
+	if *count < 0 || *duration < 0 {
+		return errors.New("-count and -duration must be positive")
+	}
+	soak := *count != 0 || *duration != 0
+	if cmd == "all" {
+		if soak {
+			return errors.New("-count and -duration apply to a single test")
+		}
+		return mainAll(fs.Args()[1:], fx, *jsonPath, *junitPath)
+	}
+	t := findTest(cmd)
+	if t == nil {
+		return fmt.Errorf("test case %q was not found", cmd)
+	}
+	sel := []selection{{t, fs.Args()[1:], true}}
+	if fx != nil {
+		fx.apply(sel)
+	}
+	if soak {
+		// The command to replay an iteration uses the same wiring; the fixture
+		// provides the arguments it derives again.
+		var replay []string
+		if *simulated {
+			replay = append(replay, "-simulate")
+		} else if *fixturePath != "" {
+			replay = append(replay, "-fixture", *fixturePath)
+		}
+		replay = append(append(replay, cmd), fs.Args()[1:]...)
+		return mainSoak(sel[0], replay, *count, *duration, *jsonPath, *junitPath)
+	}
+	if err = t.Run(newFlagSet(t, flag.ExitOnError), sel[0].args); err == nil {
+		log.Printf("Test %s successful", cmd)
+	}
+	return err
+}
+
+// mainAll runs the smoke tests listed in args, or all of them, and writes the
+// reports.
+//
+// When fx is specified, it provides the arguments of the smoke tests it
+// describes.
+func mainAll(args []string, fx *fixture, jsonPath, junitPath string) error {
+	sel, err := parseSelection(args)
+	if err != nil {
+		return err
+	}
+	if fx != nil {
+		fx.apply(sel)
+	}
+	r := runAll(sel)
+	if fx != nil {
+		r.Board = fx.Board
+	}
+	printSummary(os.Stdout, r)
+	if err = writeReports(r, jsonPath, junitPath); err != nil {
+		return err
+	}
+	if r.Failed != 0 {
+		return fmt.Errorf("%d of %d tests failed", r.Failed, len(r.Results))
+	}
+	return nil
+}
+
+// mainSoak runs a smoke test repeatedly and writes the reports.
+//
+// replay is the command line, without -seed, that reruns the smoke test.
+func mainSoak(s selection, replay []string, count int, d time.Duration, jsonPath, junitPath string) error {
+	r := runSoak(s, count, d)
+	printSoakSummary(os.Stdout, s, replay, r)
+	if err := writeReports(r, jsonPath, junitPath); err != nil {
+		return err
+	}
+	if r.Failed != 0 {
+		return fmt.Errorf("%d of %d iterations failed", r.Failed, len(r.Results))
+	}
+	return nil
+}
+
+// writeReports writes the reports requested with -json and -junit.
+func writeReports(r *report, jsonPath, junitPath string) error {
+	if jsonPath != "" {
+		if err := writeJSON(jsonPath, r); err != nil {
 			return err
 		}
 	}
-	return fmt.Errorf("test case %q was not found", cmd)
+	if junitPath != "" {
+		if err := writeJUnit(junitPath, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findTest returns the smoke test named name, if any.
+func findTest(name string) SmokeTest {
+	for _, t := range tests {
+		if t.Name() == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// newFlagSet returns the flag set to pass to t.Run().
+func newFlagSet(t SmokeTest, h flag.ErrorHandling) *flag.FlagSet {
+	f := flag.NewFlagSet("periph-smoketest "+t.Name(), h)
+	u := f.Usage
+	f.Usage = func() {
+		fmt.Printf("%s: %s\n\n", t.Name(), t.Description())
+		u()
+		flags := false
+		f.VisitAll(func(*flag.Flag) { flags = true })
+		if !flags {
+			fmt.Printf("  This smoke test doesn't have any flag.\n")
+		}
+	}
+	return f
 }
 
 func main() {
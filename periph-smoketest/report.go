@@ -0,0 +1,101 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// writeJSON writes the report as indented JSON.
+func writeJSON(path string, r *report) error {
+	raw, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(raw, '\n'), 0644)
+}
+
+// JUnit XML format, as understood by most CI systems.
+//
+// See https://github.com/testmoapp/junitxml
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Hostname  string          `xml:"hostname,attr,omitempty"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes the report as a JUnit XML file with one test suite.
+func writeJUnit(path string, r *report) error {
+	s := junitTestSuite{
+		Name:      "periph-smoketest",
+		Hostname:  r.Hostname,
+		Timestamp: r.Start.UTC().Format("2006-01-02T15:04:05"),
+		Tests:     len(r.Results),
+		Failures:  r.Failed,
+		Skipped:   r.Skipped,
+		Time:      seconds(r.Duration),
+		Cases:     make([]junitTestCase, 0, len(r.Results)),
+	}
+	for _, res := range r.Results {
+		name := res.Name
+		if res.Iteration != 0 {
+			name = fmt.Sprintf("%s #%d", res.Name, res.Iteration)
+		}
+		c := junitTestCase{
+			Name:      name,
+			Classname: "periph-smoketest",
+			Time:      seconds(res.Duration),
+			SystemOut: res.Output,
+		}
+		switch res.Status {
+		case statusFail:
+			c.Failure = &junitMessage{Message: firstLine(res.Err), Text: res.Err}
+		case statusSkip:
+			c.Skipped = &junitMessage{Message: res.Err}
+		}
+		s.Cases = append(s.Cases, c)
+	}
+	raw, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{s}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	raw = append([]byte(xml.Header), raw...)
+	return os.WriteFile(path, append(raw, '\n'), 0644)
+}
+
+// seconds formats d as seconds with a millisecond precision.
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
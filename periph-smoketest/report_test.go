@@ -0,0 +1,149 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testReport returns a report covering all the statuses.
+func testReport() *report {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("", 3600))
+	return &report{
+		Hostname: "pi",
+		Board:    "rpi4",
+		Start:    start,
+		Duration: 1500 * time.Millisecond,
+		Passed:   1,
+		Failed:   1,
+		Skipped:  1,
+		Latencies: map[string]latencyStats{
+			"read": {Count: 2, P50: time.Millisecond, P90: 2 * time.Millisecond, P99: 2 * time.Millisecond, Max: 2 * time.Millisecond},
+		},
+		Results: []result{
+			{Name: "gpio", Args: []string{"-pin1", "GPIO5"}, Status: statusPass, Start: start, Duration: 250 * time.Millisecond, Output: "ok\n", Seed: "42", Latencies: map[string][]time.Duration{"read": {time.Millisecond, 2 * time.Millisecond}}},
+			{Name: "i2c-testboard", Args: []string{}, Status: statusFail, Start: start.Add(250 * time.Millisecond), Duration: 1250*time.Millisecond + 400*time.Microsecond, Err: "read failed\n<details> & more", Output: "reading\n", Iteration: 2},
+			{Name: "bcm283x", Args: []string{}, Status: statusSkip, Start: start.Add(1500 * time.Millisecond), Err: "not running on a compatible host"},
+		},
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.xml")
+	if err := writeJUnit(path, testReport()); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != junitGolden {
+		t.Fatalf("got:\n%s\nwant:\n%s", raw, junitGolden)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := writeJSON(path, testReport()); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != jsonGolden {
+		t.Fatalf("got:\n%s\nwant:\n%s", raw, jsonGolden)
+	}
+}
+
+// junitGolden is the JUnit report of testReport(). The times are in seconds
+// and the timestamp in UTC.
+const junitGolden = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="periph-smoketest" hostname="pi" timestamp="2026-01-02T02:04:05" tests="3" failures="1" skipped="1" time="1.500">
+    <testcase name="gpio" classname="periph-smoketest" time="0.250">
+      <system-out>ok&#xA;</system-out>
+    </testcase>
+    <testcase name="i2c-testboard #2" classname="periph-smoketest" time="1.250">
+      <failure message="read failed">read failed&#xA;&lt;details&gt; &amp; more</failure>
+      <system-out>reading&#xA;</system-out>
+    </testcase>
+    <testcase name="bcm283x" classname="periph-smoketest" time="0.000">
+      <skipped message="not running on a compatible host"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+
+// jsonGolden is the JSON report of testReport(). The durations are in
+// nanoseconds.
+const jsonGolden = `{
+  "Hostname": "pi",
+  "Board": "rpi4",
+  "Start": "2026-01-02T03:04:05+01:00",
+  "Duration": 1500000000,
+  "Passed": 1,
+  "Failed": 1,
+  "Skipped": 1,
+  "Results": [
+    {
+      "Name": "gpio",
+      "Args": [
+        "-pin1",
+        "GPIO5"
+      ],
+      "Status": "pass",
+      "Start": "2026-01-02T03:04:05+01:00",
+      "Duration": 250000000,
+      "Err": "",
+      "Output": "ok\n",
+      "Seed": "42",
+      "Iteration": 0,
+      "Latencies": {
+        "read": [
+          1000000,
+          2000000
+        ]
+      }
+    },
+    {
+      "Name": "i2c-testboard",
+      "Args": [],
+      "Status": "fail",
+      "Start": "2026-01-02T03:04:05.25+01:00",
+      "Duration": 1250400000,
+      "Err": "read failed\n\u003cdetails\u003e \u0026 more",
+      "Output": "reading\n",
+      "Seed": "",
+      "Iteration": 2,
+      "Latencies": null
+    },
+    {
+      "Name": "bcm283x",
+      "Args": [],
+      "Status": "skip",
+      "Start": "2026-01-02T03:04:06.5+01:00",
+      "Duration": 0,
+      "Err": "not running on a compatible host",
+      "Output": "",
+      "Seed": "",
+      "Iteration": 0,
+      "Latencies": null
+    }
+  ],
+  "Latencies": {
+    "read": {
+      "Count": 2,
+      "P50": 1000000,
+      "P90": 2000000,
+      "P99": 2000000,
+      "Max": 2000000
+    }
+  }
+}
+`
@@ -0,0 +1,283 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"periph.io/x/host/v3/allwinner"
+	"periph.io/x/host/v3/bcm283x"
+	"periph.io/x/host/v3/chip"
+	"periph.io/x/host/v3/odroidc1"
+)
+
+// testSeparator separates the smoke tests listed after "all".
+const testSeparator = "--"
+
+// hostSpecific are the smoke tests that can only run on a specific host, and
+// the function detecting it. They are skipped on other hosts.
+var hostSpecific = map[string]func() bool{
+	"allwinner":           allwinner.Present,
+	"allwinner-benchmark": allwinner.Present,
+	"bcm283x":             bcm283x.Present,
+	"bcm283x-benchmark":   bcm283x.Present,
+	"chip":                chip.Present,
+	"odroid-c1":           odroidc1.Present,
+}
+
+// noArgs are the smoke tests that do not need arguments describing the
+// wiring, so "all" runs them when no test is listed.
+var noArgs = map[string]bool{
+	"allwinner": true,
+	"bcm283x":   true,
+	"chip":      true,
+	"odroid-c1": true,
+}
+
+// Status of a smoke test run by "all".
+const (
+	statusPass = "pass"
+	statusFail = "fail"
+	statusSkip = "skip"
+)
+
+// result is the outcome of a smoke test run by "all".
+type result struct {
+	Name string
+	Args []string
+	// Status is "pass", "fail" or "skip".
+	Status   string
+	Start    time.Time
+	Duration time.Duration
+	// Err is the error on failure or the reason why the test was skipped.
+	Err string
+	// Output is what the test printed, including its log when -v is not
+	// specified.
+	Output string
+	// Seed is the value of the -seed flag once the test ran, if it has one,
+	// to replay it.
+	Seed string
+	// Iteration is the 1-based iteration in soak mode, 0 otherwise.
+	Iteration int
+	// Latencies are the latencies measured by the test, keyed by operation,
+	// if it implements latencyReporter.
+	Latencies map[string][]time.Duration
+}
+
+// report is the outcome of "all".
+type report struct {
+	Hostname string
+	// Board is the board described by the fixture, if any.
+	Board    string
+	Start    time.Time
+	Duration time.Duration
+	Passed   int
+	Failed   int
+	Skipped  int
+	Results  []result
+	// Latencies summarizes the latencies measured by the iterations in soak
+	// mode, keyed by operation, plus "iteration" for their duration.
+	Latencies map[string]latencyStats
+}
+
+// selection is a smoke test to run and its arguments.
+type selection struct {
+	t    SmokeTest
+	args []string
+	// explicit is true when the arguments were specified, either on the
+	// command line or by the fixture. The smoke tests requiring arguments are
+	// skipped otherwise.
+	explicit bool
+}
+
+// parseSelection parses the smoke tests listed after "all", each followed by
+// its arguments and separated by testSeparator, e.g.
+// "gpio -pin1 GPIO5 -pin2 GPIO6 -- i2c-testboard -bus 1".
+//
+// It returns all the smoke tests without arguments when args is empty.
+func parseSelection(args []string) ([]selection, error) {
+	if len(args) == 0 {
+		out := make([]selection, len(tests))
+		for i := range tests {
+			out[i].t = tests[i]
+		}
+		return out, nil
+	}
+	var out []selection
+	for len(args) != 0 {
+		n := len(args)
+		for i, a := range args {
+			if a == testSeparator {
+				n = i
+				break
+			}
+		}
+		if n == 0 {
+			return nil, errors.New("expected a test name")
+		}
+		t := findTest(args[0])
+		if t == nil {
+			return nil, fmt.Errorf("test case %q was not found", args[0])
+		}
+		out = append(out, selection{t, args[1:n], true})
+		if n == len(args) {
+			break
+		}
+		if args = args[n+1:]; len(args) == 0 {
+			return nil, fmt.Errorf("expected a test name after %s", testSeparator)
+		}
+	}
+	return out, nil
+}
+
+// runAll runs the smoke tests in order and returns the report.
+func runAll(sel []selection) *report {
+	r := &report{Start: time.Now(), Results: make([]result, 0, len(sel))}
+	r.Hostname, _ = os.Hostname()
+	for _, s := range sel {
+		res := runTest(s.t, s.args, s.explicit)
+		switch res.Status {
+		case statusPass:
+			r.Passed++
+		case statusFail:
+			r.Failed++
+		case statusSkip:
+			r.Skipped++
+		}
+		r.Results = append(r.Results, res)
+	}
+	r.Duration = time.Since(r.Start)
+	return r
+}
+
+// runTest runs a single smoke test, capturing its output. A panic is reported
+// as a failure.
+func runTest(t SmokeTest, args []string, explicit bool) result {
+	res := result{Name: t.Name(), Args: args, Status: statusSkip, Start: time.Now()}
+	if args == nil {
+		res.Args = []string{}
+	}
+	if present := hostSpecific[res.Name]; present != nil && !present() {
+		res.Err = "not running on a compatible host"
+		return res
+	}
+	if !explicit && !noArgs[res.Name] {
+		res.Err = "requires arguments describing the wiring; list it explicitly or use -fixture"
+		return res
+	}
+	fmt.Printf("=== %s %s\n", res.Name, strings.Join(args, " "))
+	var err error
+	f := newFlagSet(t, flag.ContinueOnError)
+	res.Output = capture(func() {
+		defer func() {
+			if v := recover(); v != nil {
+				err = fmt.Errorf("panic: %v\n%s", v, debug.Stack())
+			}
+		}()
+		err = t.Run(f, args)
+	})
+	res.Duration = time.Since(res.Start)
+	// The smoke tests update their -seed flag when it is not specified.
+	if s := f.Lookup("seed"); s != nil && s.Value.String() != "0" {
+		res.Seed = s.Value.String()
+	}
+	if l, ok := t.(latencyReporter); ok {
+		res.Latencies = l.Latencies()
+	}
+	if err != nil {
+		res.Status = statusFail
+		res.Err = err.Error()
+	} else {
+		res.Status = statusPass
+	}
+	return res
+}
+
+// capture runs fn and returns what it wrote to os.Stdout and to the log. The
+// output is still written to os.Stdout and to the log output, if any.
+func capture(fn func()) string {
+	var out lockedBuffer
+	prevLog := log.Writer()
+	log.SetOutput(io.MultiWriter(prevLog, &out))
+	defer log.SetOutput(prevLog)
+	r, w, err := os.Pipe()
+	if err != nil {
+		// Only capture the log.
+		fn()
+		return out.String()
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = io.Copy(io.MultiWriter(stdout, &out), r)
+	}()
+	defer func() {
+		os.Stdout = stdout
+		_ = w.Close()
+		<-done
+		_ = r.Close()
+	}()
+	fn()
+	return out.String()
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use.
+type lockedBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (l *lockedBuffer) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b.Write(p)
+}
+
+func (l *lockedBuffer) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b.String()
+}
+
+// printSummary prints one line per smoke test.
+func printSummary(w io.Writer, r *report) {
+	l := 0
+	for i := range r.Results {
+		if n := len(r.Results[i].Name); n > l {
+			l = n
+		}
+	}
+	fmt.Fprintf(w, "\nSummary:\n")
+	for _, res := range r.Results {
+		switch res.Status {
+		case statusPass:
+			fmt.Fprintf(w, "  PASS %-*s %s\n", l, res.Name, res.Duration.Round(time.Millisecond))
+		case statusFail:
+			fmt.Fprintf(w, "  FAIL %-*s %s: %s\n", l, res.Name, res.Duration.Round(time.Millisecond), firstLine(res.Err))
+		case statusSkip:
+			fmt.Fprintf(w, "  SKIP %-*s %s\n", l, res.Name, res.Err)
+		}
+	}
+	fmt.Fprintf(w, "%d passed, %d failed, %d skipped in %s\n", r.Passed, r.Failed, r.Skipped, r.Duration.Round(time.Millisecond))
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
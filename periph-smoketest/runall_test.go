@@ -0,0 +1,135 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSelection(t *testing.T) {
+	got, err := parseSelection(nil)
+	if err != nil || len(got) != len(tests) {
+		t.Fatal(got, err)
+	}
+	for i := range got {
+		if got[i].t != tests[i] || got[i].args != nil || got[i].explicit {
+			t.Fatal(got[i])
+		}
+	}
+
+	got, err = parseSelection([]string{"gpio", "-pin1", "GPIO5", "-pin2", "GPIO6", "--", "i2c-testboard", "--", "spi-testboard", "-spi", "0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name string
+		args []string
+	}{
+		{"gpio", []string{"-pin1", "GPIO5", "-pin2", "GPIO6"}},
+		{"i2c-testboard", []string{}},
+		{"spi-testboard", []string{"-spi", "0"}},
+	}
+	if len(got) != len(want) {
+		t.Fatal(got)
+	}
+	for i := range want {
+		if got[i].t.Name() != want[i].name || !reflect.DeepEqual(got[i].args, want[i].args) || !got[i].explicit {
+			t.Fatalf("%d: %+v", i, got[i])
+		}
+	}
+
+	for _, args := range [][]string{
+		{"nope"},
+		{"--", "gpio"},
+		{"gpio", "--"},
+		{"gpio", "--", "--", "i2c-testboard"},
+	} {
+		if _, err = parseSelection(args); err == nil {
+			t.Errorf("%q: expected error", args)
+		}
+	}
+}
+
+// fakeTest is a SmokeTest running run.
+type fakeTest struct {
+	name string
+	run  func(f *flag.FlagSet, args []string) error
+}
+
+func (f *fakeTest) Name() string        { return f.name }
+func (f *fakeTest) Description() string { return "fake" }
+func (f *fakeTest) Run(fs *flag.FlagSet, args []string) error {
+	return f.run(fs, args)
+}
+
+func TestRunTest(t *testing.T) {
+	pass := &fakeTest{"pass", func(f *flag.FlagSet, args []string) error {
+		seed := f.Int64("seed", 0, "")
+		if err := f.Parse(args); err != nil {
+			return err
+		}
+		if *seed == 0 {
+			*seed = 42
+		}
+		fmt.Printf("printed\n")
+		log.Printf("logged")
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}}
+	res := runTest(pass, nil, true)
+	if res.Status != statusPass || res.Err != "" || res.Seed != "42" || !reflect.DeepEqual(res.Args, []string{}) {
+		t.Fatalf("%+v", res)
+	}
+	if res.Duration < 10*time.Millisecond || res.Start.IsZero() {
+		t.Fatal(res.Duration, res.Start)
+	}
+	if !strings.Contains(res.Output, "printed\n") || !strings.Contains(res.Output, "logged") {
+		t.Fatalf("%q", res.Output)
+	}
+	// The seed passed on the command line is reported as is.
+	if res = runTest(pass, []string{"-seed", "7"}, true); res.Status != statusPass || res.Seed != "7" {
+		t.Fatalf("%+v", res)
+	}
+
+	fail := &fakeTest{"fail", func(*flag.FlagSet, []string) error { return errors.New("broken\ndetails") }}
+	if res = runTest(fail, []string{"-x"}, true); res.Status != statusFail || res.Err != "broken\ndetails" || res.Seed != "" || !reflect.DeepEqual(res.Args, []string{"-x"}) {
+		t.Fatalf("%+v", res)
+	}
+
+	panics := &fakeTest{"panics", func(*flag.FlagSet, []string) error { panic("oops") }}
+	if res = runTest(panics, nil, true); res.Status != statusFail || !strings.HasPrefix(res.Err, "panic: oops\n") {
+		t.Fatalf("%+v", res)
+	}
+
+	// A test requiring arguments is skipped when none were specified, without
+	// running it.
+	if res = runTest(fail, nil, false); res.Status != statusSkip || res.Err == "" || res.Duration != 0 || res.Output != "" {
+		t.Fatalf("%+v", res)
+	}
+}
+
+func TestRunAll(t *testing.T) {
+	pass := &fakeTest{"pass", func(*flag.FlagSet, []string) error { return nil }}
+	fail := &fakeTest{"fail", func(*flag.FlagSet, []string) error { return errors.New("broken") }}
+	r := runAll([]selection{{pass, nil, true}, {fail, nil, true}, {fail, nil, false}, {pass, nil, true}})
+	if r.Passed != 2 || r.Failed != 1 || r.Skipped != 1 || len(r.Results) != 4 {
+		t.Fatalf("%+v", r)
+	}
+	for i, s := range []string{statusPass, statusFail, statusSkip, statusPass} {
+		if r.Results[i].Status != s {
+			t.Fatalf("%d: %+v", i, r.Results[i])
+		}
+	}
+	if r.Start.IsZero() || r.Duration <= 0 {
+		t.Fatal(r.Start, r.Duration)
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package simulate
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/physic"
+)
+
+// wire connects two pins together.
+type wire struct {
+	mu    sync.Mutex
+	pins  [2]*pin
+	level gpio.Level
+}
+
+// newPinPair returns two pins connected together.
+func newPinPair(name1, name2 string) (*pin, *pin) {
+	w := &wire{}
+	w.pins[0] = &pin{name: name1, w: w, edges: make(chan struct{}, 1)}
+	w.pins[1] = &pin{name: name2, w: w, edges: make(chan struct{}, 1)}
+	return w.pins[0], w.pins[1]
+}
+
+// updateLocked recalculates the level of the wire and triggers the edges.
+//
+// An output drives the wire. Otherwise a pull resistor does. Otherwise the
+// wire is floating and keeps its level, like a capacitor.
+func (w *wire) updateLocked() {
+	l := w.level
+	driven := false
+	for _, p := range w.pins {
+		if p.out {
+			l = p.level
+			driven = true
+		}
+	}
+	if !driven {
+		for _, p := range w.pins {
+			switch p.pull {
+			case gpio.PullDown:
+				l = gpio.Low
+			case gpio.PullUp:
+				l = gpio.High
+			}
+		}
+	}
+	if l == w.level {
+		return
+	}
+	w.level = l
+	for _, p := range w.pins {
+		if !p.out && (p.edge == gpio.BothEdges || (p.edge == gpio.RisingEdge && l == gpio.High) || (p.edge == gpio.FallingEdge && l == gpio.Low)) {
+			// Edges accumulated while nobody waits are merged.
+			select {
+			case p.edges <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// pin is a GPIO pin connected to another one.
+type pin struct {
+	name  string
+	w     *wire
+	edges chan struct{}
+
+	// Protected by w.mu.
+	out   bool
+	level gpio.Level
+	pull  gpio.Pull
+	edge  gpio.Edge
+}
+
+func (p *pin) String() string {
+	return p.name
+}
+
+// Halt implements conn.Resource.
+//
+// Like the sysfs driver, it stops the edge detection and flushes the
+// accumulated edge but doesn't unblock a pending WaitForEdge(), which returns
+// on timeout. See https://github.com/google/periph/issues/323.
+func (p *pin) Halt() error {
+	p.w.mu.Lock()
+	defer p.w.mu.Unlock()
+	p.edge = gpio.NoEdge
+	p.flushLocked()
+	return nil
+}
+
+func (p *pin) Name() string {
+	return p.name
+}
+
+func (p *pin) Number() int {
+	return -1
+}
+
+func (p *pin) Function() string {
+	p.w.mu.Lock()
+	defer p.w.mu.Unlock()
+	if p.out {
+		return "Out/" + p.level.String()
+	}
+	return "In/" + p.w.level.String()
+}
+
+// In implements gpio.PinIn.
+//
+// It flushes the accumulated edges.
+func (p *pin) In(pull gpio.Pull, edge gpio.Edge) error {
+	p.w.mu.Lock()
+	defer p.w.mu.Unlock()
+	p.out = false
+	if pull != gpio.PullNoChange {
+		p.pull = pull
+	}
+	p.edge = edge
+	p.flushLocked()
+	p.w.updateLocked()
+	return nil
+}
+
+func (p *pin) Read() gpio.Level {
+	p.w.mu.Lock()
+	defer p.w.mu.Unlock()
+	return p.w.level
+}
+
+// WaitForEdge implements gpio.PinIn.
+//
+// An accumulated edge is returned immediately.
+func (p *pin) WaitForEdge(timeout time.Duration) bool {
+	select {
+	case <-p.edges:
+		return true
+	default:
+	}
+	if timeout < 0 {
+		<-p.edges
+		return true
+	}
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case <-p.edges:
+		return true
+	case <-t.C:
+		return false
+	}
+}
+
+func (p *pin) Pull() gpio.Pull {
+	p.w.mu.Lock()
+	defer p.w.mu.Unlock()
+	return p.pull
+}
+
+func (p *pin) DefaultPull() gpio.Pull {
+	return gpio.Float
+}
+
+// Out implements gpio.PinOut.
+//
+// It disables edge detection.
+func (p *pin) Out(l gpio.Level) error {
+	p.w.mu.Lock()
+	defer p.w.mu.Unlock()
+	p.out = true
+	p.level = l
+	p.edge = gpio.NoEdge
+	p.flushLocked()
+	p.w.updateLocked()
+	return nil
+}
+
+func (p *pin) PWM(duty gpio.Duty, f physic.Frequency) error {
+	return errors.New("simulate: PWM is not supported")
+}
+
+func (p *pin) flushLocked() {
+	select {
+	case <-p.edges:
+	default:
+	}
+}
+
+var _ gpio.PinIO = &pin{}
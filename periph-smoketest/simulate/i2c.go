@@ -0,0 +1,263 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package simulate
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/physic"
+)
+
+// i2cDev is a device on the fake I²C bus.
+type i2cDev interface {
+	// tx is a transaction with the device. An error means the device didn't
+	// acknowledge.
+	tx(w, r []byte) error
+}
+
+// i2cBus is a fake I²C bus.
+type i2cBus struct {
+	name string
+
+	mu   sync.Mutex
+	devs map[uint16]i2cDev
+}
+
+func newI2CBus(name string) *i2cBus {
+	return &i2cBus{name: name, devs: map[uint16]i2cDev{}}
+}
+
+func (b *i2cBus) String() string {
+	return b.name
+}
+
+// Close implements i2c.BusCloser.
+//
+// It does nothing, since the bus is shared by all the openers.
+func (b *i2cBus) Close() error {
+	return nil
+}
+
+func (b *i2cBus) Tx(addr uint16, w, r []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	d := b.devs[addr]
+	if d == nil {
+		return fmt.Errorf("%s: no device at address %#x", b.name, addr)
+	}
+	if err := d.tx(w, r); err != nil {
+		return fmt.Errorf("%s: address %#x: %v", b.name, addr, err)
+	}
+	return nil
+}
+
+func (b *i2cBus) SetSpeed(f physic.Frequency) error {
+	return nil
+}
+
+// i2cEEPROM is a 24C08 8Kbit EEPROM, answering at 4 consecutive addresses,
+// one per 256 bytes block.
+//
+// Datasheet: https://www.st.com/resource/en/datasheet/m24c08-r.pdf
+type i2cEEPROM struct {
+	// wc is the write-control pin; the memory is write protected when high.
+	wc  gpio.PinIO
+	mem [1024]byte
+	ptr int
+	// busy is set while a write cycle is in progress; the device doesn't
+	// acknowledge until it completes. It completes on the next transaction,
+	// so the polling is exercised.
+	busy bool
+}
+
+func newI2CEEPROM(wc gpio.PinIO) *i2cEEPROM {
+	e := &i2cEEPROM{wc: wc}
+	for i := range e.mem {
+		e.mem[i] = 0xff
+	}
+	return e
+}
+
+// block returns the device for the block i.
+func (e *i2cEEPROM) block(i int) i2cDev {
+	return &i2cEEPROMBlock{e, i}
+}
+
+type i2cEEPROMBlock struct {
+	e *i2cEEPROM
+	i int
+}
+
+func (b *i2cEEPROMBlock) tx(w, r []byte) error {
+	e := b.e
+	if e.busy {
+		e.busy = false
+		return errors.New("write cycle in progress")
+	}
+	if len(w) != 0 {
+		e.ptr = b.i<<8 | int(w[0])
+		if data := w[1:]; len(data) != 0 {
+			if e.wc.Read() == gpio.High {
+				return errors.New("data not acknowledged; write-control is high")
+			}
+			// Writes wrap around within the 16 bytes page.
+			page := e.ptr &^ 15
+			for i, v := range data {
+				e.mem[page|(e.ptr+i)&15] = v
+			}
+			e.ptr = page | (e.ptr+len(data))&15
+			e.busy = true
+		}
+	}
+	// Reads roll over the whole memory.
+	for i := range r {
+		r[i] = e.mem[e.ptr]
+		e.ptr = (e.ptr + 1) % len(e.mem)
+	}
+	return nil
+}
+
+// ds2483 is a DS2483 I²C to 1-wire bridge.
+//
+// The 1-wire operations complete immediately, so the status register never
+// reports the bus as busy.
+//
+// Datasheet: https://datasheets.maximintegrated.com/en/ds/DS2483.pdf
+type ds2483 struct {
+	ow     *oneWireBus
+	status byte
+	config byte
+	data   byte
+	ptr    byte
+}
+
+func newDS2483(ow *oneWireBus) *ds2483 {
+	d := &ds2483{ow: ow}
+	d.reset()
+	return d
+}
+
+// DS2483 commands.
+const (
+	ds2483Reset       = 0xf0
+	ds2483SetReadPtr  = 0xe1
+	ds2483WriteConfig = 0xd2
+	ds2483AdjPort     = 0xc3
+	ds2483OWReset     = 0xb4
+	ds2483OWWrite     = 0xa5
+	ds2483OWRead      = 0x96
+	ds2483OWTriplet   = 0x78
+)
+
+// DS2483 read pointers.
+const (
+	ds2483RegStatus = 0xf0
+	ds2483RegData   = 0xe1
+	ds2483RegConfig = 0xc3
+	ds2483RegPort   = 0xb4
+)
+
+// DS2483 status register bits.
+const (
+	ds2483PPD = 0x02 // presence pulse detected
+	ds2483LL  = 0x08 // logic level of the 1-wire line
+	ds2483RST = 0x10 // device reset
+	ds2483SBR = 0x20 // single bit result
+	ds2483TSB = 0x40 // triplet second bit
+	ds2483DIR = 0x80 // branch direction taken
+)
+
+func (d *ds2483) reset() {
+	d.status = ds2483RST | ds2483LL
+	d.config = 0
+	d.ptr = ds2483RegStatus
+}
+
+func (d *ds2483) tx(w, r []byte) error {
+	if len(w) != 0 {
+		if err := d.command(w[0], w[1:]); err != nil {
+			return err
+		}
+	}
+	var v byte
+	switch d.ptr {
+	case ds2483RegStatus:
+		v = d.status
+	case ds2483RegData:
+		v = d.data
+	case ds2483RegConfig:
+		v = d.config
+	}
+	for i := range r {
+		r[i] = v
+	}
+	return nil
+}
+
+func (d *ds2483) command(cmd byte, args []byte) error {
+	switch cmd {
+	case ds2483SetReadPtr, ds2483WriteConfig, ds2483OWWrite, ds2483OWTriplet:
+		if len(args) == 0 {
+			return fmt.Errorf("command %#x: missing argument", cmd)
+		}
+	}
+	switch cmd {
+	case ds2483Reset:
+		d.reset()
+		return nil
+	case ds2483SetReadPtr:
+		switch args[0] {
+		case ds2483RegStatus, ds2483RegData, ds2483RegConfig, ds2483RegPort:
+			d.ptr = args[0]
+			return nil
+		}
+		return fmt.Errorf("invalid read pointer %#x", args[0])
+	case ds2483WriteConfig:
+		// The upper nibble must be the complement of the lower one.
+		if args[0]>>4 != ^args[0]&15 {
+			return fmt.Errorf("invalid configuration %#x", args[0])
+		}
+		d.config = args[0] & 15
+		d.status &^= ds2483RST
+		d.ptr = ds2483RegConfig
+		return nil
+	case ds2483AdjPort:
+		// The timings are irrelevant.
+		d.ptr = ds2483RegPort
+		return nil
+	case ds2483OWReset:
+		d.status &^= ds2483PPD | ds2483SBR | ds2483TSB | ds2483DIR
+		if d.ow.reset() {
+			d.status |= ds2483PPD
+		}
+	case ds2483OWWrite:
+		d.ow.write(args[0])
+	case ds2483OWRead:
+		d.data = d.ow.read()
+	case ds2483OWTriplet:
+		gotZero, gotOne, taken := d.ow.triplet(args[0] >> 7)
+		d.status &^= ds2483SBR | ds2483TSB | ds2483DIR
+		// The bits read are the wired-AND of the devices' bits.
+		if !gotZero {
+			d.status |= ds2483SBR
+		}
+		if !gotOne {
+			d.status |= ds2483TSB
+		}
+		if taken != 0 {
+			d.status |= ds2483DIR
+		}
+	default:
+		return fmt.Errorf("unsupported command %#x", cmd)
+	}
+	d.ptr = ds2483RegStatus
+	return nil
+}
+
+var _ i2c.BusCloser = &i2cBus{}
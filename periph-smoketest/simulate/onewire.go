@@ -0,0 +1,364 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package simulate
+
+import (
+	"errors"
+	"sync"
+
+	"periph.io/x/conn/v3/onewire"
+)
+
+// oneWireDev is a device on the fake 1-wire bus.
+//
+// The bus handles the ROM commands; the device only sees the bytes
+// exchanged once it is selected.
+type oneWireDev interface {
+	addr() onewire.Address
+	// reset is called on a bus reset.
+	reset()
+	write(b byte)
+	read() byte
+}
+
+// oneWireBus is a fake 1-wire bus, emulated at the byte level.
+//
+// It implements onewire.Bus so it can be used directly, and it is also
+// driven by the fake DS2483.
+type oneWireBus struct {
+	mu   sync.Mutex
+	devs []oneWireDev
+
+	state    int
+	match    []byte
+	selected []oneWireDev
+	// Search state.
+	searching []oneWireDev
+	bit       int
+}
+
+// States of the bus after a reset.
+const (
+	owIdle     = iota // waiting for a reset
+	owROM             // waiting for a ROM command
+	owMatch           // reading the address of a "match ROM" command
+	owSelected        // the selected devices receive the bytes
+	owSearch          // search triplets
+)
+
+// ROM commands.
+const (
+	owMatchROM    = 0x55
+	owSkipROM     = 0xcc
+	owSearchROM   = 0xf0
+	owAlarmSearch = 0xec
+)
+
+func newOneWireBus(devs ...oneWireDev) *oneWireBus {
+	return &oneWireBus{devs: devs}
+}
+
+func (b *oneWireBus) String() string {
+	return "SIM_ONEWIRE"
+}
+
+// Tx implements onewire.Bus.
+func (b *oneWireBus) Tx(w, r []byte, power onewire.Pullup) error {
+	if !b.reset() {
+		return errors.New("simulate: no device present")
+	}
+	for _, v := range w {
+		b.write(v)
+	}
+	for i := range r {
+		r[i] = b.read()
+	}
+	return nil
+}
+
+// Search implements onewire.Bus.
+func (b *oneWireBus) Search(alarmOnly bool) ([]onewire.Address, error) {
+	return onewire.Search(b, alarmOnly)
+}
+
+// SearchTriplet implements onewire.BusSearcher.
+func (b *oneWireBus) SearchTriplet(direction byte) (onewire.TripletResult, error) {
+	gotZero, gotOne, taken := b.triplet(direction)
+	return onewire.TripletResult{GotZero: gotZero, GotOne: gotOne, Taken: taken}, nil
+}
+
+// reset resets the bus and returns true if a device is present.
+func (b *oneWireBus) reset() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = owROM
+	b.selected = nil
+	for _, d := range b.devs {
+		d.reset()
+	}
+	return len(b.devs) != 0
+}
+
+func (b *oneWireBus) write(v byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case owROM:
+		switch v {
+		case owMatchROM:
+			b.state = owMatch
+			b.match = b.match[:0]
+		case owSkipROM:
+			b.state = owSelected
+			b.selected = b.devs
+		case owSearchROM:
+			b.state = owSearch
+			b.searching = b.devs
+			b.bit = 0
+		case owAlarmSearch:
+			// No device is ever in alarm state.
+			b.state = owSearch
+			b.searching = nil
+			b.bit = 0
+		default:
+			b.state = owIdle
+		}
+	case owMatch:
+		if b.match = append(b.match, v); len(b.match) == 8 {
+			var a onewire.Address
+			for i := 7; i >= 0; i-- {
+				a = a<<8 | onewire.Address(b.match[i])
+			}
+			for _, d := range b.devs {
+				if d.addr() == a {
+					b.selected = append(b.selected, d)
+				}
+			}
+			b.state = owSelected
+		}
+	case owSelected:
+		for _, d := range b.selected {
+			d.write(v)
+		}
+	}
+}
+
+// read returns the wired-AND of the bytes sent by the selected devices; the
+// line stays high when none is.
+func (b *oneWireBus) read() byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	v := byte(0xff)
+	if b.state == owSelected {
+		for _, d := range b.selected {
+			v &= d.read()
+		}
+	}
+	return v
+}
+
+// triplet does a search triplet: it returns whether a device has a zero bit,
+// whether a device has a one bit and the direction taken, the remaining
+// devices being the ones with this bit.
+func (b *oneWireBus) triplet(direction byte) (bool, bool, byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != owSearch || b.bit == 64 {
+		return false, false, 1
+	}
+	gotZero, gotOne := false, false
+	for _, d := range b.searching {
+		if d.addr()>>uint(b.bit)&1 == 0 {
+			gotZero = true
+		} else {
+			gotOne = true
+		}
+	}
+	taken := direction & 1
+	if !gotZero || !gotOne {
+		taken = 1
+		if gotZero {
+			taken = 0
+		}
+	}
+	var next []oneWireDev
+	for _, d := range b.searching {
+		if byte(d.addr()>>uint(b.bit)&1) == taken {
+			next = append(next, d)
+		}
+	}
+	b.searching = next
+	b.bit++
+	return gotZero, gotOne, taken
+}
+
+// newAddress returns the address of a device, with its CRC.
+func newAddress(family byte, serial uint64) onewire.Address {
+	var raw [8]byte
+	raw[0] = family
+	for i := 1; i < 7; i++ {
+		raw[i] = byte(serial >> uint(8*(i-1)))
+	}
+	raw[7] = onewire.CalcCRC(raw[:7])
+	var a onewire.Address
+	for i := 7; i >= 0; i-- {
+		a = a<<8 | onewire.Address(raw[i])
+	}
+	return a
+}
+
+// ds18b20 is a DS18B20 temperature sensor, always reading ds18b20Temp once
+// a conversion was done.
+//
+// Datasheet: https://datasheets.maximintegrated.com/en/ds/DS18B20.pdf
+type ds18b20 struct {
+	a    onewire.Address
+	spad [9]byte
+	cmd  byte
+	// n is the number of bytes exchanged since the function command.
+	n int
+}
+
+// ds18b20Temp is the temperature reported, in 1/16°C.
+const ds18b20Temp = 22*16 + 5
+
+func newDS18B20(serial uint64) *ds18b20 {
+	// Power-on values; 85°C until a conversion is done, 12 bits resolution.
+	d := &ds18b20{a: newAddress(0x28, serial), spad: [9]byte{0x50, 0x05, 0x4b, 0x46, 0x7f, 0xff, 0x0c, 0x10}}
+	d.updateCRC()
+	return d
+}
+
+func (d *ds18b20) addr() onewire.Address {
+	return d.a
+}
+
+func (d *ds18b20) reset() {
+	d.cmd = 0
+}
+
+func (d *ds18b20) write(v byte) {
+	if d.cmd == 0 {
+		d.cmd = v
+		d.n = 0
+		if v == 0x44 {
+			// Convert T; the unused bits are undefined at a lower resolution,
+			// clear them.
+			bits := 9 + int(d.spad[4]>>5&3)
+			t := int16(ds18b20Temp) &^ (1<<uint(12-bits) - 1)
+			d.spad[0] = byte(t)
+			d.spad[1] = byte(t >> 8)
+			d.updateCRC()
+		}
+		return
+	}
+	if d.cmd == 0x4e && d.n < 3 {
+		// Write scratchpad: TH, TL, configuration.
+		if d.n == 2 {
+			v = v&0x60 | 0x1f
+		}
+		d.spad[2+d.n] = v
+		d.n++
+		d.updateCRC()
+	}
+}
+
+func (d *ds18b20) read() byte {
+	if d.cmd == 0xbe && d.n < len(d.spad) {
+		// Read scratchpad.
+		d.n++
+		return d.spad[d.n-1]
+	}
+	return 0xff
+}
+
+func (d *ds18b20) updateCRC() {
+	d.spad[8] = onewire.CalcCRC(d.spad[:8])
+}
+
+// ds2431 is a DS2431 1Kbit EEPROM. Only the scratchpad commands are
+// emulated.
+//
+// Datasheet: https://datasheets.maximintegrated.com/en/ds/DS2431.pdf
+type ds2431 struct {
+	a    onewire.Address
+	ta   [2]byte
+	es   byte
+	spad [8]byte
+	cmd  byte
+	// n is the number of bytes exchanged since the function command.
+	n   int
+	out []byte
+}
+
+func newDS2431(serial uint64) *ds2431 {
+	return &ds2431{a: newAddress(0x2d, serial)}
+}
+
+func (d *ds2431) addr() onewire.Address {
+	return d.a
+}
+
+func (d *ds2431) reset() {
+	d.cmd = 0
+	d.out = nil
+}
+
+func (d *ds2431) write(v byte) {
+	if d.cmd == 0 {
+		d.cmd = v
+		d.n = 0
+		d.out = nil
+		if v == 0xaa {
+			// Read scratchpad: TA1, TA2, E/S, the data and the inverted CRC16.
+			d.out = append([]byte{d.ta[0], d.ta[1], d.es}, d.spad[:]...)
+			crc := ^crc16(append([]byte{v}, d.out...))
+			d.out = append(d.out, byte(crc), byte(crc>>8))
+		}
+		return
+	}
+	if d.cmd == 0x0f {
+		// Write scratchpad: TA1, TA2 then up to 8 bytes of data.
+		switch {
+		case d.n < 2:
+			d.ta[d.n] = v
+		case d.n < 2+len(d.spad):
+			off := int(d.ta[0]&7) + d.n - 2
+			if off < len(d.spad) {
+				d.spad[off] = v
+				d.es = d.ta[0]&^7 | byte(off)
+			}
+		}
+		d.n++
+	}
+}
+
+func (d *ds2431) read() byte {
+	if len(d.out) == 0 {
+		return 0xff
+	}
+	v := d.out[0]
+	d.out = d.out[1:]
+	return v
+}
+
+// crc16 is the 1-wire CRC16, as described in Maxim application note 27.
+func crc16(buf []byte) uint16 {
+	var crc uint16
+	for _, b := range buf {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xa001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
+
+var _ onewire.Bus = &oneWireBus{}
+var _ onewire.BusSearcher = &oneWireBus{}
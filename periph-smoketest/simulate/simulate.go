@@ -0,0 +1,88 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package simulate registers in-process fakes of the hardware used by the
+// smoke tests, so they can run without a periph-tester board.
+//
+// It emulates:
+//   - two cross-connected GPIO pins, with pulls and edge detection, for
+//     gpiosmoketest.
+//   - an I²C bus with a 24C08 EEPROM at 0x50-0x53 and a DS2483 at 0x18, for
+//     i2csmoketest. The DS2483 has a DS18B20 and a DS2431 on its 1-wire bus,
+//     for onewiresmoketest.
+//   - a SPI port with a M95080 EEPROM, for spismoketest.
+//
+// The fakes behave like the periph-tester board as expected by the smoke
+// tests; they are not cycle accurate.
+//
+// See https://github.com/periph/periph-tester
+package simulate
+
+import (
+	"sync"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/conn/v3/gpio/gpiotest"
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/i2c/i2creg"
+	"periph.io/x/conn/v3/spi"
+	"periph.io/x/conn/v3/spi/spireg"
+)
+
+// Names of the fakes registered by Register.
+const (
+	// GPIO1 and GPIO2 are connected together.
+	GPIO1 = "SIM_GPIO1"
+	GPIO2 = "SIM_GPIO2"
+	// I2CBus hosts the I²C EEPROM and the DS2483.
+	I2CBus = "SIM_I2C"
+	// I2CWC is the I²C EEPROM write-control pin.
+	I2CWC = "SIM_I2C_WC"
+	// SPIPort hosts the SPI EEPROM.
+	SPIPort = "SIM_SPI"
+	// SPIWP is the SPI EEPROM write-protect pin.
+	SPIWP = "SIM_SPI_WP"
+)
+
+// Register registers the fakes in gpioreg, i2creg and spireg.
+//
+// It is safe to call multiple times; the fakes are registered once and keep
+// their state, like the EEPROMs' content, for the lifetime of the process.
+func Register() error {
+	once.Do(func() { errRegister = register() })
+	return errRegister
+}
+
+//
+
+var (
+	once        sync.Once
+	errRegister error
+)
+
+func register() error {
+	p1, p2 := newPinPair(GPIO1, GPIO2)
+	wc := &gpiotest.Pin{N: I2CWC, Num: -1, Fn: "Out", L: gpio.High}
+	wp := &gpiotest.Pin{N: SPIWP, Num: -1, Fn: "Out", L: gpio.Low}
+	for _, p := range []gpio.PinIO{p1, p2, wc, wp} {
+		if err := gpioreg.Register(p); err != nil {
+			return err
+		}
+	}
+
+	ow := newOneWireBus(newDS18B20(0x0000065a2b3c), newDS2431(0x00000f1e2d3c))
+	b := newI2CBus(I2CBus)
+	e := newI2CEEPROM(wc)
+	for i := uint16(0); i < 4; i++ {
+		b.devs[0x50+i] = e.block(int(i))
+	}
+	b.devs[0x18] = newDS2483(ow)
+	if err := i2creg.Register(I2CBus, nil, -1, func() (i2c.BusCloser, error) { return b, nil }); err != nil {
+		return err
+	}
+
+	p := &spiPort{name: SPIPort, c: &spiConn{name: SPIPort, e: newSPIEEPROM(wp)}}
+	return spireg.Register(SPIPort, nil, -1, func() (spi.PortCloser, error) { return p, nil })
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package simulate
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"periph.io/x/cmd/periph-smoketest/gpiosmoketest"
+	"periph.io/x/cmd/periph-smoketest/i2csmoketest"
+	"periph.io/x/cmd/periph-smoketest/onewiresmoketest"
+	"periph.io/x/cmd/periph-smoketest/spismoketest"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/onewire"
+)
+
+// TestSmokeTests runs the smoke tests against the fakes, as
+// "periph-smoketest -simulate" does.
+func TestSmokeTests(t *testing.T) {
+	if err := Register(); err != nil {
+		t.Fatal(err)
+	}
+	// The smoke tests log and print a lot.
+	prev := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(prev)
+	vcd := filepath.Join(t.TempDir(), "gpio.vcd")
+	data := []struct {
+		name string
+		t    interface {
+			Run(f *flag.FlagSet, args []string) error
+		}
+		args []string
+	}{
+		{"gpio", &gpiosmoketest.SmokeTest{}, []string{"-pin1", GPIO1, "-pin2", GPIO2, "-vcd", vcd}},
+		{"i2c-testboard", &i2csmoketest.SmokeTest{}, []string{"-bus", I2CBus, "-wc", I2CWC, "-seed", "1"}},
+		{"onewire-testboard", &onewiresmoketest.SmokeTest{}, []string{"-i2cbus", I2CBus, "-seed", "1"}},
+		{"spi-testboard", &spismoketest.SmokeTest{}, []string{"-spi", SPIPort, "-wp", SPIWP, "-seed", "1"}},
+	}
+	for _, line := range data {
+		line := line
+		t.Run(line.name, func(t *testing.T) {
+			if testing.Short() && line.name == "gpio" {
+				t.Skip("takes several seconds")
+			}
+			f := flag.NewFlagSet(line.name, flag.ContinueOnError)
+			if err := line.t.Run(f, line.args); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+	if !testing.Short() {
+		raw, err := os.ReadFile(vcd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(raw); !strings.Contains(s, "$scope module "+GPIO1+" $end") || !strings.Contains(s, "$enddefinitions $end") {
+			t.Fatalf("unexpected trace:\n%s", s)
+		}
+	}
+}
+
+func TestPinPair(t *testing.T) {
+	p1, p2 := newPinPair("A", "B")
+	if err := p1.In(gpio.Float, gpio.RisingEdge); err != nil {
+		t.Fatal(err)
+	}
+	if err := p2.In(gpio.PullUp, gpio.NoEdge); err != nil {
+		t.Fatal(err)
+	}
+	if l := p1.Read(); l != gpio.High {
+		t.Fatalf("pull up: got %s", l)
+	}
+	if !p1.WaitForEdge(0) {
+		t.Fatal("expected a rising edge")
+	}
+	if err := p2.Out(gpio.Low); err != nil {
+		t.Fatal(err)
+	}
+	if p1.WaitForEdge(0) {
+		t.Fatal("unexpected falling edge")
+	}
+	// Halt flushes the accumulated edge and stops the edge detection.
+	if err := p2.Out(gpio.High); err != nil {
+		t.Fatal(err)
+	}
+	if err := p1.Halt(); err != nil {
+		t.Fatal(err)
+	}
+	if p1.WaitForEdge(0) {
+		t.Fatal("accumulated edge not flushed by Halt")
+	}
+	if err := p2.Out(gpio.Low); err != nil {
+		t.Fatal(err)
+	}
+	if err := p2.Out(gpio.High); err != nil {
+		t.Fatal(err)
+	}
+	if p1.WaitForEdge(0) {
+		t.Fatal("unexpected edge after Halt")
+	}
+	// Like the real drivers, Halt doesn't unblock a pending WaitForEdge,
+	// which times out.
+	if err := p1.In(gpio.Float, gpio.BothEdges); err != nil {
+		t.Fatal(err)
+	}
+	const timeout = 50 * time.Millisecond
+	start := time.Now()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = p1.Halt()
+	}()
+	if p1.WaitForEdge(timeout) {
+		t.Fatal("unexpected edge")
+	}
+	if d := time.Since(start); d < timeout {
+		t.Fatalf("WaitForEdge returned after %s", d)
+	}
+	if err := p2.Out(gpio.Low); err != nil {
+		t.Fatal(err)
+	}
+	// Floating keeps the level.
+	if err := p2.In(gpio.Float, gpio.NoEdge); err != nil {
+		t.Fatal(err)
+	}
+	if l := p1.Read(); l != gpio.Low {
+		t.Fatalf("floating: got %s", l)
+	}
+}
+
+func TestOneWireBus(t *testing.T) {
+	t1, e1 := newDS18B20(1), newDS2431(2)
+	b := newOneWireBus(t1, e1, newDS2431(3))
+	addrs, err := b.Search(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 3 {
+		t.Fatalf("expected 3 devices, got %#x", addrs)
+	}
+	for _, a := range addrs {
+		raw := make([]byte, 8)
+		for i := range raw {
+			raw[i] = byte(a >> uint(8*i))
+		}
+		if !onewire.CheckCRC(raw) {
+			t.Fatalf("invalid address %#x", a)
+		}
+	}
+	if addrs, err = b.Search(true); err == nil || len(addrs) != 0 {
+		t.Fatalf("expected no device in alarm state, got %#x, %v", addrs, err)
+	}
+
+	d := onewire.Dev{Bus: b, Addr: e1.addr()}
+	if err = d.Tx([]byte{0x0f, 0, 0, 1, 2, 3, 4, 5, 6, 7, 8}, nil); err != nil {
+		t.Fatal(err)
+	}
+	var spad [13]byte
+	if err = d.Tx([]byte{0xaa}, spad[:]); err != nil {
+		t.Fatal(err)
+	}
+	if want := [11]byte{0, 0, 7, 1, 2, 3, 4, 5, 6, 7, 8}; [11]byte(spad[:11]) != want {
+		t.Fatalf("scratchpad: got %#x, want %#x", spad[:11], want)
+	}
+	// The inverted CRC16 of the command and the data makes the CRC16 of the
+	// whole read 0xb001.
+	if c := crc16(append([]byte{0xaa}, spad[:]...)); c != 0xb001 {
+		t.Fatalf("scratchpad CRC16: got %#x", c)
+	}
+}
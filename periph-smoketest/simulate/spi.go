@@ -0,0 +1,200 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package simulate
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"periph.io/x/conn/v3"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+)
+
+// spiPort is a fake SPI port with a single device.
+type spiPort struct {
+	name string
+	c    *spiConn
+}
+
+func (p *spiPort) String() string {
+	return p.name
+}
+
+// Close implements spi.PortCloser.
+//
+// It does nothing, since the port is shared by all the openers.
+func (p *spiPort) Close() error {
+	return nil
+}
+
+func (p *spiPort) LimitSpeed(f physic.Frequency) error {
+	return nil
+}
+
+func (p *spiPort) Connect(f physic.Frequency, mode spi.Mode, bits int) (spi.Conn, error) {
+	if m := mode &^ (spi.HalfDuplex | spi.NoCS | spi.LSBFirst); m != spi.Mode0 && m != spi.Mode3 {
+		return nil, fmt.Errorf("%s: unsupported mode %s", p.name, mode)
+	}
+	if bits != 8 {
+		return nil, fmt.Errorf("%s: unsupported bits per word %d", p.name, bits)
+	}
+	return p.c, nil
+}
+
+// spiConn is the connection to the fake SPI EEPROM.
+type spiConn struct {
+	name string
+
+	mu sync.Mutex
+	e  *spiEEPROM
+}
+
+func (c *spiConn) String() string {
+	return c.name
+}
+
+func (c *spiConn) Duplex() conn.Duplex {
+	return conn.Full
+}
+
+// Tx implements conn.Conn.
+//
+// Like the Linux driver, r may be nil but otherwise must have the same length
+// as w.
+func (c *spiConn) Tx(w, r []byte) error {
+	if len(w) == 0 {
+		return errors.New("simulate: Tx() with an empty w")
+	}
+	if len(r) != 0 && len(r) != len(w) {
+		return fmt.Errorf("simulate: Tx(): when both w and r are used, they must be the same size; got %d and %d bytes", len(w), len(r))
+	}
+	if r == nil {
+		r = make([]byte, len(w))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.e.tx(w, r)
+	return nil
+}
+
+func (c *spiConn) TxPackets(p []spi.Packet) error {
+	return errors.New("simulate: TxPackets() is not supported")
+}
+
+// spiEEPROM is a M95080 8Kbit EEPROM.
+//
+// As expected by spismoketest, the write-protect pin protects the whole
+// memory when low, not only the status register.
+//
+// Datasheet: https://www.st.com/resource/en/datasheet/m95080-r.pdf
+type spiEEPROM struct {
+	wp     gpio.PinIO
+	mem    [1024]byte
+	status byte
+}
+
+// M95080 commands.
+const (
+	m95WriteStatus = 0x01
+	m95Write       = 0x02
+	m95Read        = 0x03
+	m95WriteDis    = 0x04
+	m95ReadStatus  = 0x05
+	m95WriteEn     = 0x06
+)
+
+// M95080 status register bits.
+const (
+	m95WIP = 0x01 // write in progress
+	m95WEL = 0x02 // write enable latch
+	m95BP  = 0x0c // block protect
+)
+
+func newSPIEEPROM(wp gpio.PinIO) *spiEEPROM {
+	e := &spiEEPROM{wp: wp}
+	for i := range e.mem {
+		e.mem[i] = 0xff
+	}
+	return e
+}
+
+// tx is a transaction, from chip select to chip deselect. The output is high
+// when the chip doesn't drive it.
+func (e *spiEEPROM) tx(w, r []byte) {
+	for i := range r {
+		r[i] = 0xff
+	}
+	status := e.status
+	// The write cycle completes after being polled once, so the polling is
+	// exercised.
+	e.status &^= m95WIP
+	if status&m95WIP != 0 && w[0] != m95ReadStatus {
+		// Only the status can be read during a write cycle.
+		return
+	}
+	writable := status&m95WEL != 0 && e.wp.Read() == gpio.High
+	switch w[0] {
+	case m95WriteEn:
+		e.status |= m95WEL
+	case m95WriteDis:
+		e.status &^= m95WEL
+	case m95ReadStatus:
+		for i := 1; i < len(r); i++ {
+			r[i] = status
+		}
+	case m95WriteStatus:
+		if len(w) < 2 || !writable {
+			return
+		}
+		e.status = e.status&^m95BP | w[1]&m95BP | m95WIP
+		e.status &^= m95WEL
+	case m95Read:
+		if len(w) < 3 {
+			return
+		}
+		addr := e.address(w)
+		for i := 3; i < len(r); i++ {
+			r[i] = e.mem[addr]
+			addr = (addr + 1) % len(e.mem)
+		}
+	case m95Write:
+		if len(w) < 4 || !writable {
+			return
+		}
+		addr := e.address(w)
+		// Writes wrap around within the 32 bytes page.
+		page := addr &^ 31
+		for i, v := range w[3:] {
+			if a := page | (addr+i)&31; !e.protected(a) {
+				e.mem[a] = v
+			}
+		}
+		e.status = e.status&^m95WEL | m95WIP
+	}
+}
+
+func (e *spiEEPROM) address(w []byte) int {
+	return (int(w[1])<<8 | int(w[2])) % len(e.mem)
+}
+
+// protected returns true if the address is protected by the block protect
+// bits: the upper quarter, half or the whole memory.
+func (e *spiEEPROM) protected(addr int) bool {
+	switch e.status & m95BP {
+	case 0x04:
+		return addr >= len(e.mem)*3/4
+	case 0x08:
+		return addr >= len(e.mem)/2
+	case 0x0c:
+		return true
+	}
+	return false
+}
+
+var _ spi.PortCloser = &spiPort{}
+var _ spi.Conn = &spiConn{}
@@ -0,0 +1,173 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+)
+
+// latencyReporter is optionally implemented by a SmokeTest to report the
+// latency of operations measured during the last Run, e.g. edge detection.
+type latencyReporter interface {
+	Latencies() map[string][]time.Duration
+}
+
+// latencyStats summarizes latency samples.
+type latencyStats struct {
+	Count int
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// newLatencyStats returns the statistics of samples. It sorts samples.
+func newLatencyStats(samples []time.Duration) latencyStats {
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	return latencyStats{
+		Count: len(samples),
+		P50:   percentile(samples, 50),
+		P90:   percentile(samples, 90),
+		P99:   percentile(samples, 99),
+		Max:   percentile(samples, 100),
+	}
+}
+
+// percentile returns the nearest-rank percentile p of the sorted samples.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+// iterationKey is the key in report.Latencies for the duration of the
+// iterations.
+const iterationKey = "iteration"
+
+// runSoak runs the smoke test repeatedly, until count iterations ran or d
+// elapsed. A zero value means no limit but at least one must be specified.
+//
+// An interrupt (Ctrl-C) stops after the current iteration, so the summary is
+// still printed; a second one aborts.
+//
+// The output of the iterations that passed is not kept in the results, to
+// bound the memory used over a long run.
+func runSoak(s selection, count int, d time.Duration) *report {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	defer signal.Stop(c)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-c:
+			signal.Stop(c)
+			fmt.Fprintf(os.Stderr, "\nStopping after this iteration; interrupt again to abort.\n")
+			close(stop)
+		case <-done:
+		}
+	}()
+
+	r := &report{Start: time.Now(), Latencies: map[string]latencyStats{}}
+	r.Hostname, _ = os.Hostname()
+	samples := map[string][]time.Duration{}
+loop:
+	for i := 1; count == 0 || i <= count; i++ {
+		if d != 0 && time.Since(r.Start) >= d {
+			break
+		}
+		select {
+		case <-stop:
+			break loop
+		default:
+		}
+		fmt.Printf("=== iteration %d\n", i)
+		res := runTest(s.t, s.args, true)
+		res.Iteration = i
+		switch res.Status {
+		case statusPass:
+			r.Passed++
+			res.Output = ""
+		case statusFail:
+			r.Failed++
+		case statusSkip:
+			r.Skipped++
+		}
+		samples[iterationKey] = append(samples[iterationKey], res.Duration)
+		for k, v := range res.Latencies {
+			samples[k] = append(samples[k], v...)
+		}
+		r.Results = append(r.Results, res)
+	}
+	r.Duration = time.Since(r.Start)
+	for k, v := range samples {
+		r.Latencies[k] = newLatencyStats(v)
+	}
+	return r
+}
+
+// printSoakSummary prints the failure rate, the first failure and how to
+// replay it with the command line replay, and the latency percentiles.
+func printSoakSummary(w io.Writer, s selection, replay []string, r *report) {
+	fmt.Fprintf(w, "\nSoak summary for %s: %d iterations in %s\n", s.t.Name(), len(r.Results), r.Duration.Round(time.Millisecond))
+	rate := 0.
+	if len(r.Results) != 0 {
+		rate = 100 * float64(r.Failed) / float64(len(r.Results))
+	}
+	fmt.Fprintf(w, "  %d passed, %d failed (%.2f%%), %d skipped\n", r.Passed, r.Failed, rate, r.Skipped)
+	for _, res := range r.Results {
+		if res.Status != statusFail {
+			continue
+		}
+		fmt.Fprintf(w, "  First failure: iteration %d after %s: %s\n", res.Iteration, res.Start.Sub(r.Start).Round(time.Millisecond), firstLine(res.Err))
+		if res.Seed != "" {
+			args := append(append([]string{}, replay...), "-seed", res.Seed)
+			fmt.Fprintf(w, "  Replay with: periph-smoketest %s\n", strings.Join(args, " "))
+		}
+		break
+	}
+	keys := make([]string, 0, len(r.Latencies))
+	for k := range r.Latencies {
+		if k != iterationKey {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	keys = append(keys, iterationKey)
+	l := 0
+	for _, k := range keys {
+		if len(k) > l {
+			l = len(k)
+		}
+	}
+	fmt.Fprintf(w, "  %-*s %7s %10s %10s %10s %10s\n", l, "Latency", "count", "p50", "p90", "p99", "max")
+	for _, k := range keys {
+		st := r.Latencies[k]
+		fmt.Fprintf(w, "  %-*s %7d %10s %10s %10s %10s\n", l, k, st.Count, roundLatency(st.P50), roundLatency(st.P90), roundLatency(st.P99), roundLatency(st.Max))
+	}
+}
+
+// roundLatency rounds d to 3 significant digits, enough to be readable.
+func roundLatency(d time.Duration) time.Duration {
+	for r := time.Duration(1); r < time.Hour; r *= 10 {
+		if d < 1000*r {
+			return d.Round(r)
+		}
+	}
+	return d.Round(time.Second)
+}
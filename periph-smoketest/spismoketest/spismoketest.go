@@ -221,16 +221,13 @@ func (s *SmokeTest) eeprom(d spi.Conn, wpPin gpio.PinIO) error {
 	if err := wpPin.Out(gpio.Low); err != nil {
 		return err
 	}
-	if err := d.Tx([]byte{0x10, 0xA5}, nil); err == nil {
-		return errors.New("eeprom: write with write-control disabled didn't return an error")
-	}
-	// Write the value of the second byte in the just-written page into the first byte.
-	first := rBuf[0]
-	second := rBuf[1]
+	// Write the complement of the first byte in the just-written page. The SPI
+	// bus cannot report an error, so read it back to confirm it didn't change.
+	first := rBuf[3]
 	if err := d.Tx([]byte{cmdWriteEnable}, rBuf[:1]); err != nil {
 		return err
 	}
-	if err := d.Tx([]byte{cmdWriteMemory, addr[0], addr[1], second}, rBuf[:4]); err != nil {
+	if err := d.Tx([]byte{cmdWriteMemory, addr[0], addr[1], ^first}, rBuf[:4]); err != nil {
 		return err
 	}
 	// Read byte back after the chip is ready.
@@ -0,0 +1,172 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// auditSystemUser is the user recorded for the pins restored by the server
+// itself.
+const auditSystemUser = "system"
+
+// auditEntry is a line of the audit log.
+type auditEntry struct {
+	Time time.Time
+	// User is the authenticated user, the remote IP, the peer uid on a Unix
+	// domain socket, "mqtt" or auditSystemUser.
+	User   string
+	Remote string
+	// Endpoint is the URL path, the MQTT topic or why the server restored
+	// pins.
+	Endpoint string
+	Args     interface{}
+	Status   int
+	Result   interface{}
+}
+
+// auditLog is an append-only JSON lines log of the API calls that change the
+// state of the GPIOs.
+//
+// The file is rotated when it reaches maxSize: <path> is renamed to <path>.1,
+// <path>.1 to <path>.2 and so on, keeping at most keep old files.
+type auditLog struct {
+	path    string
+	maxSize int64
+	keep    int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// openAuditLog opens the audit log at path, appending to it.
+func openAuditLog(path string, maxSize int64, keep int) (*auditLog, error) {
+	a := &auditLog{path: path, maxSize: maxSize, keep: keep}
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *auditLog) open() error {
+	f, err := os.OpenFile(a.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	a.f = f
+	a.size = fi.Size()
+	return nil
+}
+
+// record appends an entry for the call by c. It does nothing when a is nil,
+// i.e. when the audit log is disabled.
+//
+// A failure to write the audit log is logged but doesn't fail the call, which
+// has already been done.
+func (a *auditLog) record(c *caller, args, result interface{}, status int) {
+	if a == nil {
+		return
+	}
+	e := auditEntry{
+		Time:     time.Now().UTC(),
+		User:     c.user,
+		Remote:   c.remote,
+		Endpoint: c.endpoint,
+		Args:     args,
+		Status:   status,
+		Result:   result,
+	}
+	raw, err := json.Marshal(&e)
+	if err != nil {
+		log.Printf("audit: %v", err)
+		return
+	}
+	raw = append(raw, '\n')
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err = a.writeLocked(raw); err != nil {
+		log.Printf("audit: %v", err)
+	}
+}
+
+// recordRestore records the pins restored by the server for reason, with the
+// error restoring each of them, if any. Like record, it does nothing when a is
+// nil.
+func (a *auditLog) recordRestore(reason string, names []string, errs []error) {
+	if a == nil || len(names) == 0 {
+		return
+	}
+	out := make([]string, len(errs))
+	for i, err := range errs {
+		if err != nil {
+			out[i] = err.Error()
+		}
+	}
+	a.record(&caller{user: auditSystemUser, endpoint: reason}, names, out, 200)
+}
+
+func (a *auditLog) writeLocked(raw []byte) error {
+	if a.f == nil {
+		// A previous rotation failed; retry.
+		if err := a.open(); err != nil {
+			return err
+		}
+	}
+	if a.size != 0 && a.size+int64(len(raw)) > a.maxSize {
+		if err := a.rotateLocked(); err != nil {
+			return err
+		}
+	}
+	n, err := a.f.Write(raw)
+	a.size += int64(n)
+	return err
+}
+
+// rotateLocked renames the current file and starts a new one.
+func (a *auditLog) rotateLocked() error {
+	err := a.f.Close()
+	a.f = nil
+	if err != nil {
+		return err
+	}
+	if a.keep <= 0 {
+		if err = os.Remove(a.path); err != nil {
+			return err
+		}
+	} else {
+		_ = os.Remove(fmt.Sprintf("%s.%d", a.path, a.keep))
+		for i := a.keep - 1; i > 0; i-- {
+			if err = os.Rename(fmt.Sprintf("%s.%d", a.path, i), fmt.Sprintf("%s.%d", a.path, i+1)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err = os.Rename(a.path, a.path+".1"); err != nil {
+			return err
+		}
+	}
+	return a.open()
+}
+
+func (a *auditLog) close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.f == nil {
+		return nil
+	}
+	err := a.f.Close()
+	a.f = nil
+	return err
+}
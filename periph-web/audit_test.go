@@ -0,0 +1,183 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// readAudit returns the entries of the audit log file path.
+func readAudit(t *testing.T, path string) []auditEntry {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var out []auditEntry
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		var e auditEntry
+		if err = json.Unmarshal(s.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, e)
+	}
+	if err = s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestAuditRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	a, err := openAuditLog(path, 1<<20, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.close()
+	// A nil log is disabled.
+	var disabled *auditLog
+	disabled.record(&caller{}, nil, nil, 200)
+	disabled.recordRestore("shutdown", []string{"GPIO1"}, []error{nil})
+
+	c := &caller{user: "alice", remote: "192.0.2.1:1234", endpoint: "/api/periph/v1/gpio/out"}
+	a.record(c, map[string]bool{"GPIO1": true}, []string{""}, 200)
+	a.recordRestore("shutdown", []string{"GPIO1", "GPIO2"}, []error{nil, errors.New("failed")})
+	// Nothing was restored.
+	a.recordRestore("lease/expire", nil, nil)
+	got := readAudit(t, path)
+	if len(got) != 2 {
+		t.Fatal(got)
+	}
+	for i := range got {
+		if time.Since(got[i].Time) > time.Minute || got[i].Time.Location() != time.UTC {
+			t.Fatal(got[i].Time)
+		}
+		got[i].Time = time.Time{}
+	}
+	want := []auditEntry{
+		{
+			User:     "alice",
+			Remote:   "192.0.2.1:1234",
+			Endpoint: "/api/periph/v1/gpio/out",
+			Args:     map[string]interface{}{"GPIO1": true},
+			Status:   200,
+			Result:   []interface{}{""},
+		},
+		{
+			User:     "system",
+			Endpoint: "shutdown",
+			Args:     []interface{}{"GPIO1", "GPIO2"},
+			Status:   200,
+			Result:   []interface{}{"", "failed"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("%+v", got)
+	}
+}
+
+func TestAuditRotate(t *testing.T) {
+	for _, keep := range []int{0, 2} {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		// Every entry is larger than half the maximum size, so each one goes
+		// in its own file.
+		a, err := openAuditLog(path, 100, keep)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 1; i <= 5; i++ {
+			a.record(&caller{user: "alice", endpoint: "/api/periph/v1/gpio/out"}, nil, nil, i)
+		}
+		if err = a.close(); err != nil {
+			t.Fatal(err)
+		}
+		// The current file has the last entry, the previous files the ones
+		// before it, up to keep files.
+		for i := 0; i <= keep; i++ {
+			p := path
+			if i != 0 {
+				p += "." + strconv.Itoa(i)
+			}
+			got := readAudit(t, p)
+			if len(got) != 1 || got[0].Status != 5-i {
+				t.Fatalf("keep=%d: %s: %+v", keep, p, got)
+			}
+		}
+		if _, err = os.Stat(path + "." + strconv.Itoa(keep+1)); !os.IsNotExist(err) {
+			t.Fatalf("keep=%d: %v", keep, err)
+		}
+		// Reopening appends.
+		if a, err = openAuditLog(path, 1<<20, keep); err != nil {
+			t.Fatal(err)
+		}
+		a.record(&caller{}, nil, nil, 6)
+		_ = a.close()
+		if got := readAudit(t, path); len(got) != 2 || got[1].Status != 6 {
+			t.Fatalf("keep=%d: %+v", keep, got)
+		}
+	}
+}
+
+// TestWebAudit verifies the entries of the calls changing the GPIOs and of
+// the pins restored by the server.
+func TestWebAudit(t *testing.T) {
+	registerFakes(t)
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	a, err := openAuditLog(path, 1<<20, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.close()
+	ts := newTestServer(t, &webOpts{audit: a})
+
+	// The results are in the order of the pin names, like the arguments.
+	var out []string
+	ts.post(t, "/api/periph/v1/gpio/out", map[string]bool{"WEB2": true, "NOPE": false, "WEB_ALIAS": false}, &out, 200)
+	if !reflect.DeepEqual(out, []string{"Pin not found", "", ""}) {
+		t.Fatal(out)
+	}
+	var l leaseOut
+	ts.post(t, "/api/periph/v1/lease/acquire", &leaseIn{Pins: []string{"WEB1"}}, &l, 200)
+	ts.post(t, "/api/periph/v1/lease/release", &leaseRef{ID: l.ID}, &l, 200)
+	j := &ts.s.apis
+	if _, err = j.leases.acquire(&caller{user: "test"}, &leaseIn{Pins: []string{"WEB2"}}, time.Millisecond, j.expireLease); err != nil {
+		t.Fatal(err)
+	}
+	for len(j.leases.list()) != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if err = j.restorePins(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readAudit(t, path)
+	if len(got) != 4 {
+		t.Fatalf("%+v", got)
+	}
+	if got[0].Endpoint != "/api/periph/v1/gpio/out" ||
+		!reflect.DeepEqual(got[0].Args, map[string]interface{}{"NOPE": false, "WEB2": true, "WEB_ALIAS": false}) ||
+		!reflect.DeepEqual(got[0].Result, []interface{}{"Pin not found", "", ""}) {
+		t.Fatalf("%+v", got[0])
+	}
+	if got[1].User != "system" || got[1].Endpoint != "lease/release" || !reflect.DeepEqual(got[1].Args, []interface{}{"WEB1"}) || !reflect.DeepEqual(got[1].Result, []interface{}{""}) {
+		t.Fatalf("%+v", got[1])
+	}
+	if got[2].User != "system" || got[2].Endpoint != "lease/expire" || !reflect.DeepEqual(got[2].Args, []interface{}{"WEB2"}) {
+		t.Fatalf("%+v", got[2])
+	}
+	if got[3].User != "system" || got[3].Endpoint != "shutdown" || !reflect.DeepEqual(got[3].Args, []interface{}{"WEB1", "WEB2"}) {
+		t.Fatalf("%+v", got[3])
+	}
+}
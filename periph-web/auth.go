@@ -0,0 +1,213 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// authCacheDuration is how long a successful basic authentication is cached,
+// so bcrypt is not run on every single request.
+const authCacheDuration = 5 * time.Minute
+
+// authenticator validates the credentials of HTTP requests.
+//
+// It supports pre-shared bearer tokens and htpasswd-style bcrypt users.
+type authenticator struct {
+	// tokens are the bearer tokens; token -> user.
+	tokens map[string]string
+	// users are the basic authentication users; user -> bcrypt hash.
+	users map[string][]byte
+
+	mu sync.Mutex
+	// cache is the successful basic authentications; sha256(user:password) ->
+	// expiration.
+	cache map[[sha256.Size]byte]time.Time
+}
+
+// loadAuth loads the authentication configuration from a comma separated list
+// of sources.
+//
+// Each source is one of:
+//   - "token:<path>": a file of "user:token" lines, to be used as
+//     "Authorization: Bearer <token>".
+//   - "htpasswd:<path>": a file of "user:bcrypt hash" lines as generated by
+//     "htpasswd -B", to be used with HTTP basic authentication.
+func loadAuth(spec string) (*authenticator, error) {
+	a := &authenticator{
+		tokens: map[string]string{},
+		users:  map[string][]byte{},
+		cache:  map[[sha256.Size]byte]time.Time{},
+	}
+	for _, src := range strings.Split(spec, ",") {
+		i := strings.IndexByte(src, ':')
+		if i < 0 {
+			return nil, fmt.Errorf("invalid auth source %q; expected token:<path> or htpasswd:<path>", src)
+		}
+		kind, path := src[:i], src[i+1:]
+		switch kind {
+		case "token":
+			if err := readUserFile(path, func(user, token string) error {
+				if _, ok := a.tokens[token]; ok {
+					return errors.New("duplicate token")
+				}
+				a.tokens[token] = user
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		case "htpasswd":
+			if err := readUserFile(path, func(user, hash string) error {
+				if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+					return fmt.Errorf("user %q: only bcrypt hashes are supported: %v", user, err)
+				}
+				a.users[user] = []byte(hash)
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unknown auth source %q", kind)
+		}
+	}
+	if len(a.tokens) == 0 && len(a.users) == 0 {
+		return nil, errors.New("no credential was loaded")
+	}
+	return a, nil
+}
+
+// readUserFile reads a file of "user:secret" lines. Empty lines and lines
+// starting with # are ignored.
+func readUserFile(path string, add func(user, secret string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for n := 1; s.Scan(); n++ {
+		l := strings.TrimSpace(s.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		i := strings.IndexByte(l, ':')
+		if i <= 0 || i == len(l)-1 {
+			return fmt.Errorf("%s:%d: expected user:secret", path, n)
+		}
+		if err = add(l[:i], l[i+1:]); err != nil {
+			return fmt.Errorf("%s:%d: %v", path, n, err)
+		}
+	}
+	return s.Err()
+}
+
+// authenticate returns the user authenticated by the request, if any.
+func (a *authenticator) authenticate(r *http.Request) (string, bool) {
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+		return a.checkToken(strings.TrimPrefix(h, "Bearer "))
+	}
+	if user, password, ok := r.BasicAuth(); ok {
+		return user, a.checkPassword(user, password)
+	}
+	return "", false
+}
+
+func (a *authenticator) checkToken(token string) (string, bool) {
+	// Go through all the tokens to not leak timing information.
+	user := ""
+	for t, u := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			user = u
+		}
+	}
+	return user, user != ""
+}
+
+func (a *authenticator) checkPassword(user, password string) bool {
+	hash, ok := a.users[user]
+	if !ok {
+		return false
+	}
+	key := sha256.Sum256([]byte(user + ":" + password))
+	now := time.Now()
+	a.mu.Lock()
+	exp, ok := a.cache[key]
+	a.mu.Unlock()
+	if ok && now.Before(exp) {
+		return true
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil {
+		return false
+	}
+	a.mu.Lock()
+	for k, e := range a.cache {
+		if now.After(e) {
+			delete(a.cache, k)
+		}
+	}
+	a.cache[key] = now.Add(authCacheDuration)
+	a.mu.Unlock()
+	return true
+}
+
+// userKey is the context key for the authenticated user.
+type userKey struct{}
+
+// requestUser returns the authenticated user of the request, if any.
+func requestUser(r *http.Request) string {
+	u, _ := r.Context().Value(userKey{}).(string)
+	return u
+}
+
+// userID returns the identity to bind the XSRF token to. It is the
+// authenticated user, or the peer's uid on a Unix domain socket, or the remote
+// IP.
+func userID(r *http.Request) string {
+	if u := requestUser(r); u != "" {
+		return "user:" + u
+	}
+	if p, _ := r.Context().Value(peerKey{}).(string); p != "" {
+		return p
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// requireAuth disallows requests that are not authenticated.
+//
+// It must be the front line decorator, along localOnly.
+func (a *authenticator) requireAuth(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := a.authenticate(r)
+		if !ok {
+			log.Printf("Unauthenticated request from %s", r.RemoteAddr)
+			if len(a.users) != 0 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="periph-web", charset="UTF-8"`)
+			} else {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="periph-web"`)
+			}
+			http.Error(w, "authentication required", http.StatusUnauthorized)
+			_ = r.Body.Close()
+			return
+		}
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+	})
+}
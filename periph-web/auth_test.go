@@ -0,0 +1,237 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"periph.io/x/conn/v3/driver/driverreg"
+)
+
+func TestLoadAuth(t *testing.T) {
+	dir := t.TempDir()
+	tokens := writeFile(t, dir, "tokens", "# comment\n\nalice:atoken\nbob:btoken\n")
+	htpasswd := writeFile(t, dir, "htpasswd", "carol:"+bcryptHash(t, "secret")+"\n")
+	a, err := loadAuth("token:" + tokens + ",htpasswd:" + htpasswd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.tokens) != 2 || a.tokens["atoken"] != "alice" || len(a.users) != 1 {
+		t.Fatal(a.tokens, a.users)
+	}
+	for _, c := range []struct {
+		spec, want string
+	}{
+		{"nope", "invalid auth source"},
+		{"nope:" + tokens, "unknown auth source"},
+		{"token:" + filepath.Join(dir, "missing"), "no such file"},
+		{"token:" + writeFile(t, dir, "dup", "a:t\nb:t\n"), "dup:2: duplicate token"},
+		{"token:" + writeFile(t, dir, "bad", "a\n"), "bad:1: expected user:secret"},
+		{"htpasswd:" + writeFile(t, dir, "md5", "a:$apr1$x$y\n"), "only bcrypt hashes are supported"},
+		{"token:" + writeFile(t, dir, "empty", "# nobody\n"), "no credential was loaded"},
+	} {
+		if _, err := loadAuth(c.spec); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: %v", c.spec, err)
+		}
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	a := newTestAuth(t)
+	for _, c := range []struct {
+		name     string
+		set      func(r *http.Request)
+		user     string
+		accepted bool
+	}{
+		{"token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer atoken") }, "alice", true},
+		{"bad token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, "", false},
+		{"token prefix", func(r *http.Request) { r.Header.Set("Authorization", "Bearer atoke") }, "", false},
+		{"empty token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer ") }, "", false},
+		{"password", func(r *http.Request) { r.SetBasicAuth("carol", "secret") }, "carol", true},
+		{"bad password", func(r *http.Request) { r.SetBasicAuth("carol", "nope") }, "carol", false},
+		{"unknown user", func(r *http.Request) { r.SetBasicAuth("dave", "secret") }, "dave", false},
+		{"token as password", func(r *http.Request) { r.SetBasicAuth("alice", "atoken") }, "alice", false},
+		{"none", func(r *http.Request) {}, "", false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			r := newRequest(t, "GET", "http://localhost/", "")
+			c.set(r)
+			if user, ok := a.authenticate(r); ok != c.accepted || (ok && user != c.user) {
+				t.Fatalf("got %q, %t", user, ok)
+			}
+		})
+	}
+}
+
+func TestCheckPasswordCache(t *testing.T) {
+	a := newTestAuth(t)
+	if !a.checkPassword("carol", "secret") {
+		t.Fatal("valid password rejected")
+	}
+	key := sha256.Sum256([]byte("carol:secret"))
+	if _, ok := a.cache[key]; !ok || len(a.cache) != 1 {
+		t.Fatal("the authentication was not cached")
+	}
+	if a.checkPassword("carol", "nope") || len(a.cache) != 1 {
+		t.Fatal("a failed authentication must not be cached")
+	}
+	// Change the hash: the cached authentication is used without bcrypt.
+	a.users["carol"] = []byte(bcryptHash(t, "other"))
+	if !a.checkPassword("carol", "secret") {
+		t.Fatal("the cache was not used")
+	}
+	// Once expired, bcrypt is used again.
+	a.cache[key] = time.Now().Add(-time.Second)
+	if a.checkPassword("carol", "secret") {
+		t.Fatal("an expired authentication was used")
+	}
+	if !a.checkPassword("carol", "other") {
+		t.Fatal("valid password rejected")
+	}
+	if _, ok := a.cache[key]; ok {
+		t.Fatal("the expired authentication was not evicted")
+	}
+	// A removed user can't use the cache.
+	delete(a.users, "carol")
+	if a.checkPassword("carol", "other") {
+		t.Fatal("removed user accepted")
+	}
+}
+
+// TestWebAuth verifies that the server requires the credentials and binds the
+// XSRF tokens to the authenticated user.
+func TestWebAuth(t *testing.T) {
+	for _, c := range []struct {
+		name, challenge string
+		onlyTokens      bool
+	}{
+		{"basic", `Basic realm="periph-web", charset="UTF-8"`, false},
+		{"bearer", `Bearer realm="periph-web"`, true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			a := newTestAuth(t)
+			if c.onlyTokens {
+				a.users = map[string][]byte{}
+			}
+			url := newAuthServer(t, a)
+			for _, set := range []func(r *http.Request){
+				func(r *http.Request) {},
+				func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") },
+				func(r *http.Request) { r.SetBasicAuth("carol", "nope") },
+			} {
+				req := newRequest(t, "POST", url+"/raw/periph/v1/xsrf_token", "")
+				set(req)
+				resp, err := http.DefaultClient.Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				_ = resp.Body.Close()
+				if resp.StatusCode != 401 || resp.Header.Get("WWW-Authenticate") != c.challenge {
+					t.Fatal(resp.StatusCode, resp.Header)
+				}
+			}
+		})
+	}
+
+	url := newAuthServer(t, newTestAuth(t))
+	auth := map[string]func(r *http.Request){
+		"alice": func(r *http.Request) { r.Header.Set("Authorization", "Bearer atoken") },
+		"bob":   func(r *http.Request) { r.Header.Set("Authorization", "Bearer btoken") },
+		"carol": func(r *http.Request) { r.SetBasicAuth("carol", "secret") },
+	}
+	req := newRequest(t, "POST", url+"/raw/periph/v1/xsrf_token", "")
+	auth["alice"](req)
+	status, token := do(t, req)
+	if status != 200 {
+		t.Fatal(status, token)
+	}
+	for _, c := range []struct {
+		user   string
+		status int
+	}{
+		{"alice", 200},
+		// A token minted for a user can't be used by another one.
+		{"bob", 400},
+		{"carol", 400},
+	} {
+		req := newRequest(t, "POST", url+"/api/periph/v1/server/state", "{}")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(xsrfHeader, token)
+		auth[c.user](req)
+		if status, body := do(t, req); status != c.status {
+			t.Fatalf("%s: %d %s", c.user, status, body)
+		}
+	}
+}
+
+// TestUserID verifies the identity the XSRF tokens are bound to.
+func TestUserID(t *testing.T) {
+	r := newRequest(t, "GET", "http://localhost/", "")
+	r.RemoteAddr = "192.0.2.1:1234"
+	if id := userID(r); id != "192.0.2.1" {
+		t.Fatal(id)
+	}
+	a := newTestAuth(t)
+	var got string
+	h := a.requireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = userID(r) }))
+	r.Header.Set("Authorization", "Bearer btoken")
+	h.ServeHTTP(nil, r)
+	if got != "user:bob" {
+		t.Fatal(got)
+	}
+}
+
+// newTestAuth returns an authenticator with the tokens of alice and bob and
+// the password of carol.
+func newTestAuth(t *testing.T) *authenticator {
+	dir := t.TempDir()
+	tokens := writeFile(t, dir, "tokens", "alice:atoken\nbob:btoken\n")
+	htpasswd := writeFile(t, dir, "htpasswd", "carol:"+bcryptHash(t, "secret")+"\n")
+	a, err := loadAuth("token:" + tokens + ",htpasswd:" + htpasswd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// newAuthServer starts a server requiring the authentication a and returns
+// its URL.
+func newAuthServer(t *testing.T, a *authenticator) string {
+	s, err := newWebServer("127.0.0.1:0", &driverreg.State{}, &webOpts{auth: a})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := s.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return "http://" + s.server.Addr
+}
+
+func bcryptHash(t *testing.T, password string) string {
+	h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(h)
+}
+
+// writeFile writes content to the file name in dir and returns its path.
+func writeFile(t *testing.T, dir, name, content string) string {
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
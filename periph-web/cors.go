@@ -0,0 +1,85 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// xsrfHeader is the header to send the XSRF token in instead of the
+// XSRF-TOKEN cookie, for clients on another origin. Since it is not a
+// CORS-safelisted header, a browser only sends it cross-origin when the
+// preflight request was allowed.
+const xsrfHeader = "X-XSRF-Token"
+
+// corsMaxAge is how long, in seconds, a browser may cache a preflight
+// response.
+const corsMaxAge = "600"
+
+// parseCORS parses a comma separated list of origins, e.g.
+// "https://dashboard.example.com,http://localhost:3000", or "*" to allow any
+// origin. newWebServer only accepts "*" with authentication.
+func parseCORS(spec string) ([]string, error) {
+	var out []string
+	for _, o := range strings.Split(spec, ",") {
+		if o == "*" {
+			out = append(out, o)
+			continue
+		}
+		u, err := url.Parse(o)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+			return nil, fmt.Errorf("invalid origin %q; expected scheme://host[:port] or *", o)
+		}
+		// Browsers send the origin without a trailing slash and in lower case.
+		out = append(out, strings.ToLower(u.Scheme+"://"+u.Host))
+	}
+	return out, nil
+}
+
+// corsHandler allows the browsers to call the APIs from the origins listed.
+//
+// Preflight requests are answered without calling h, since browsers do not
+// send the credentials with them. It must be in front of requireAuth.
+//
+// Credentials are not allowed, so the XSRF-TOKEN cookie is not sent
+// cross-origin; the clients send the token in the xsrfHeader header instead.
+func corsHandler(origins []string, h http.Handler) http.Handler {
+	allowed := func(o string) bool {
+		for _, a := range origins {
+			if a == "*" || a == o {
+				return true
+			}
+		}
+		return false
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		o := r.Header.Get("Origin")
+		if o == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		ok := allowed(o)
+		if ok {
+			w.Header().Set("Access-Control-Allow-Origin", o)
+		}
+		if r.Method != "OPTIONS" || r.Header.Get("Access-Control-Request-Method") == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		_ = r.Body.Close()
+		if !ok {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, "+xsrfHeader+", "+leaseHeader)
+		w.Header().Set("Access-Control-Max-Age", corsMaxAge)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"periph.io/x/conn/v3/driver/driverreg"
+)
+
+func TestParseCORS(t *testing.T) {
+	got, err := parseCORS("https://Dashboard.example.com/,http://localhost:3000,*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"https://dashboard.example.com", "http://localhost:3000", "*"}; !reflect.DeepEqual(got, want) {
+		t.Fatal(got)
+	}
+	for _, spec := range []string{"", "example.com", "ftp://example.com", "https://", "https://u:p@example.com", "https://example.com/path", "https://example.com?q", "https://example.com#f"} {
+		if _, err = parseCORS(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+func TestCORSHandler(t *testing.T) {
+	calls := 0
+	h := corsHandler([]string{"https://dashboard.example.com"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(method, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/periph/v1/gpio/read", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if method == "OPTIONS" {
+			req.Header.Set("Access-Control-Request-Method", "POST")
+			req.Header.Set("Access-Control-Request-Headers", "content-type,x-xsrf-token")
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	// Preflight from an allowed origin, answered without calling h.
+	w := serve("OPTIONS", "https://dashboard.example.com")
+	if w.Code != http.StatusNoContent || calls != 0 {
+		t.Fatal(w.Code, calls)
+	}
+	for k, v := range map[string]string{
+		"Access-Control-Allow-Origin":  "https://dashboard.example.com",
+		"Access-Control-Allow-Methods": "GET, POST",
+		"Access-Control-Allow-Headers": "Authorization, Content-Type, X-XSRF-Token, X-Periph-Lease",
+		"Access-Control-Max-Age":       corsMaxAge,
+		"Vary":                         "Origin",
+	} {
+		if got := w.Header().Get(k); got != v {
+			t.Errorf("%s: %q", k, got)
+		}
+	}
+	// Preflight from another origin.
+	w = serve("OPTIONS", "https://evil.example.com")
+	if w.Code != http.StatusForbidden || calls != 0 {
+		t.Fatal(w.Code, calls)
+	}
+	if w.Header().Get("Access-Control-Allow-Origin") != "" || w.Header().Get("Access-Control-Allow-Methods") != "" || w.Header().Get("Vary") != "Origin" {
+		t.Fatal(w.Header())
+	}
+	// The actual requests reach h; the browser only exposes the response to
+	// the allowed origin.
+	w = serve("POST", "https://dashboard.example.com")
+	if w.Code != http.StatusOK || calls != 1 || w.Header().Get("Access-Control-Allow-Origin") != "https://dashboard.example.com" || w.Header().Get("Vary") != "Origin" {
+		t.Fatal(w.Code, calls, w.Header())
+	}
+	w = serve("POST", "https://evil.example.com")
+	if w.Code != http.StatusOK || calls != 2 || w.Header().Get("Access-Control-Allow-Origin") != "" || w.Header().Get("Vary") != "Origin" {
+		t.Fatal(w.Code, calls, w.Header())
+	}
+	// Same origin requests are not affected.
+	w = serve("POST", "")
+	if w.Code != http.StatusOK || calls != 3 || len(w.Header()) != 0 {
+		t.Fatal(w.Code, calls, w.Header())
+	}
+}
+
+// TestWebCORSAny verifies that any origin is only allowed with
+// authentication, and that the preflight requests don't need it.
+func TestWebCORSAny(t *testing.T) {
+	if s, err := newWebServer("127.0.0.1:0", &driverreg.State{}, &webOpts{cors: []string{"*"}}); err == nil {
+		_ = s.Close()
+		t.Fatal("expected error")
+	}
+	s, err := newWebServer("127.0.0.1:0", &driverreg.State{}, &webOpts{cors: []string{"*"}, auth: newTestAuth(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	req := newRequest(t, "OPTIONS", "http://"+s.server.Addr+"/api/periph/v1/gpio/read", "")
+	req.Header.Set("Origin", "https://any.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent || resp.Header.Get("Access-Control-Allow-Origin") != "https://any.example.com" {
+		t.Fatal(resp.StatusCode, resp.Header)
+	}
+	// The API itself still requires the credentials.
+	req = newRequest(t, "POST", "http://"+s.server.Addr+"/api/periph/v1/gpio/read", "[]")
+	req.Header.Set("Origin", "https://any.example.com")
+	if status, _ := do(t, req); status != http.StatusUnauthorized {
+		t.Fatal(status)
+	}
+}
@@ -0,0 +1,409 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"regexp"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+	"periph.io/x/conn/v3/display"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/conn/v3/i2c/i2creg"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi/spireg"
+	"periph.io/x/devices/v3/bmxx80"
+	"periph.io/x/devices/v3/ina219"
+	"periph.io/x/devices/v3/ssd1306"
+)
+
+// devicesConfig is the content of the file passed to -devices, in YAML or
+// JSON.
+type devicesConfig struct {
+	Devices []deviceConfig
+}
+
+// deviceConfig describes a device attached to the host.
+//
+// Exactly one of I2C or SPI must be set.
+type deviceConfig struct {
+	// Name is the name used in the URL, e.g. /api/periph/v1/devices/<name>/sense.
+	// It must match deviceNameRe.
+	Name string
+	// Type is one of "bmxx80", "ina219" or "ssd1306".
+	Type string
+	// I2C is the name of the I²C bus the device is on.
+	I2C string
+	// Addr is the address of the device on the I²C bus. It defaults to the
+	// driver's default address. A ssd1306 only supports its default address.
+	Addr uint16
+	// SPI is the name of the SPI port the device is on.
+	SPI string
+	// DC is the name of the data/command GPIO for a ssd1306 in 4-wire SPI mode.
+	DC string
+	// W and H are the size of a display. They default to 128x64.
+	W int
+	H int
+}
+
+// deviceNameRe matches the valid device names. They are used as is in the URL
+// patterns registered on the mux, which panics on some characters like a space
+// or braces.
+var deviceNameRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// sensorValue is a single measurement of a sensor.
+type sensorValue struct {
+	// Quantity is what is measured, e.g. "temperature".
+	Quantity string
+	// Value is the measurement in Unit.
+	Value float64
+	Unit  string
+	// Text is the measurement formatted by periph.
+	Text string
+}
+
+// device is a device loaded from the configuration file.
+//
+// At least one of sense or drawer is set.
+type device struct {
+	cfg    deviceConfig
+	closer io.Closer
+	// keys are the resource keys of the bus and pins used by the device, to
+	// honor the leases.
+	keys []string
+
+	mu     sync.Mutex
+	sense  func() ([]sensorValue, error)
+	drawer display.Drawer
+}
+
+// loadDevices reads the configuration file and opens all the devices in it.
+func loadDevices(path string) ([]*device, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg devicesConfig
+	if err = decodeYAML(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	var out []*device
+	names := map[string]bool{}
+	for _, c := range cfg.Devices {
+		var dev *device
+		if names[c.Name] {
+			err = errors.New("duplicate name")
+		} else {
+			dev, err = openDevice(c)
+		}
+		if err != nil {
+			closeDevices(out)
+			return nil, fmt.Errorf("%s: device %q: %v", path, c.Name, err)
+		}
+		names[c.Name] = true
+		out = append(out, dev)
+	}
+	return out, nil
+}
+
+// decodeYAML decodes the YAML document raw into v. Since JSON is a subset of
+// YAML, raw can also be JSON.
+//
+// The document goes through encoding/json, so the field names are the same in
+// both formats and the unknown fields are rejected.
+func decodeYAML(raw []byte, v interface{}) error {
+	var doc interface{}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+	j, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(j))
+	d.DisallowUnknownFields()
+	return d.Decode(v)
+}
+
+// closeDevices closes the buses and ports used by the devices.
+func closeDevices(devs []*device) {
+	for _, d := range devs {
+		_ = d.closer.Close()
+	}
+}
+
+func openDevice(c deviceConfig) (*device, error) {
+	if c.Name == "" {
+		return nil, errors.New("Name is required")
+	}
+	if !deviceNameRe.MatchString(c.Name) {
+		return nil, errors.New("Name may only contain letters, digits, '_' and '-'")
+	}
+	if (c.I2C == "") == (c.SPI == "") {
+		return nil, errors.New("exactly one of I2C or SPI must be specified")
+	}
+	if c.Addr != 0 && c.I2C == "" {
+		return nil, errors.New("Addr requires I2C")
+	}
+	d := &device{cfg: c}
+	if c.I2C != "" {
+		k, err := i2cKey(c.I2C)
+		if err != nil {
+			return nil, err
+		}
+		d.keys = append(d.keys, k)
+	} else {
+		k, err := spiKey(c.SPI)
+		if err != nil {
+			return nil, err
+		}
+		d.keys = append(d.keys, k)
+	}
+	switch c.Type {
+	case "bmxx80":
+		if err := d.openBMxx80(); err != nil {
+			return nil, err
+		}
+	case "ina219":
+		if err := d.openINA219(); err != nil {
+			return nil, err
+		}
+	case "ssd1306":
+		if err := d.openSSD1306(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported Type %q", c.Type)
+	}
+	return d, nil
+}
+
+func (d *device) openBMxx80() error {
+	var dev *bmxx80.Dev
+	if d.cfg.I2C != "" {
+		b, err := i2creg.Open(d.cfg.I2C)
+		if err != nil {
+			return err
+		}
+		addr := d.cfg.Addr
+		if addr == 0 {
+			addr = 0x76
+		}
+		if dev, err = bmxx80.NewI2C(b, addr, &bmxx80.DefaultOpts); err != nil {
+			_ = b.Close()
+			return err
+		}
+		d.closer = b
+	} else {
+		p, err := spireg.Open(d.cfg.SPI)
+		if err != nil {
+			return err
+		}
+		if dev, err = bmxx80.NewSPI(p, &bmxx80.DefaultOpts); err != nil {
+			_ = p.Close()
+			return err
+		}
+		d.closer = p
+	}
+	d.sense = func() ([]sensorValue, error) {
+		var e physic.Env
+		if err := dev.Sense(&e); err != nil {
+			return nil, err
+		}
+		out := []sensorValue{
+			{"temperature", float64(e.Temperature-physic.ZeroCelsius) / float64(physic.Celsius), "celsius", e.Temperature.String()},
+			{"pressure", float64(e.Pressure) / float64(physic.Pascal), "pascal", e.Pressure.String()},
+		}
+		if e.Humidity != 0 {
+			out = append(out, sensorValue{"humidity", float64(e.Humidity) / float64(physic.PercentRH), "percent", e.Humidity.String()})
+		}
+		return out, nil
+	}
+	return nil
+}
+
+func (d *device) openINA219() error {
+	if d.cfg.I2C == "" {
+		return errors.New("ina219 requires I2C")
+	}
+	b, err := i2creg.Open(d.cfg.I2C)
+	if err != nil {
+		return err
+	}
+	opts := ina219.DefaultOpts
+	if d.cfg.Addr != 0 {
+		opts.Address = int(d.cfg.Addr)
+	}
+	dev, err := ina219.New(b, &opts)
+	if err != nil {
+		_ = b.Close()
+		return err
+	}
+	d.closer = b
+	d.sense = func() ([]sensorValue, error) {
+		p, err := dev.Sense()
+		if err != nil {
+			return nil, err
+		}
+		return []sensorValue{
+			{"shunt", float64(p.Shunt) / float64(physic.Volt), "volt", p.Shunt.String()},
+			{"voltage", float64(p.Voltage) / float64(physic.Volt), "volt", p.Voltage.String()},
+			{"current", float64(p.Current) / float64(physic.Ampere), "ampere", p.Current.String()},
+			{"power", float64(p.Power) / float64(physic.Watt), "watt", p.Power.String()},
+		}, nil
+	}
+	return nil
+}
+
+func (d *device) openSSD1306() error {
+	opts := ssd1306.DefaultOpts
+	if d.cfg.W != 0 {
+		opts.W = d.cfg.W
+	}
+	if d.cfg.H != 0 {
+		opts.H = d.cfg.H
+	}
+	var dev *ssd1306.Dev
+	if d.cfg.I2C != "" {
+		// The driver hardcodes the address.
+		if d.cfg.Addr != 0 && d.cfg.Addr != 0x3C {
+			return errors.New("ssd1306 only supports Addr 0x3C")
+		}
+		b, err := i2creg.Open(d.cfg.I2C)
+		if err != nil {
+			return err
+		}
+		if dev, err = ssd1306.NewI2C(b, &opts); err != nil {
+			_ = b.Close()
+			return err
+		}
+		d.closer = b
+	} else {
+		var dc gpio.PinOut
+		if d.cfg.DC != "" {
+			p := gpioreg.ByName(d.cfg.DC)
+			if p == nil {
+				return fmt.Errorf("DC pin %q not found", d.cfg.DC)
+			}
+			dc = p
+			d.keys = append(d.keys, pinKey(p))
+		}
+		p, err := spireg.Open(d.cfg.SPI)
+		if err != nil {
+			return err
+		}
+		if dev, err = ssd1306.NewSPI(p, dc, &opts); err != nil {
+			_ = p.Close()
+			return err
+		}
+		d.closer = p
+	}
+	d.drawer = dev
+	return nil
+}
+
+// /api/periph/v1/devices/list
+
+type deviceRef struct {
+	Name  string
+	Type  string
+	Sense bool
+	Draw  bool
+}
+
+func (j *jsonAPI) apiDevicesList() ([]deviceRef, int) {
+	out := make([]deviceRef, 0, len(j.devices))
+	for _, d := range j.devices {
+		out = append(out, deviceRef{d.cfg.Name, d.cfg.Type, d.sense != nil, d.drawer != nil})
+	}
+	return out, 200
+}
+
+// /api/periph/v1/devices/<name>/sense
+
+type senseOut struct {
+	Values []sensorValue
+	Err    string
+}
+
+// apiDeviceSense reads the sensor d. It returns 409 if its bus is leased by
+// another client.
+func (j *jsonAPI) apiDeviceSense(c *caller, d *device) (*senseOut, int) {
+	v, err := j.senseDevice(c, d)
+	if err != nil {
+		if _, ok := err.(*leaseConflict); ok {
+			return &senseOut{Err: err.Error()}, 409
+		}
+		return &senseOut{Err: err.Error()}, 200
+	}
+	return &senseOut{Values: v}, 200
+}
+
+// senseDevice reads the sensor d while holding the locks of its bus.
+//
+// The background readers, like the history sampler, pass the zero caller so
+// they don't disturb a client leasing the bus.
+func (j *jsonAPI) senseDevice(c *caller, d *device) ([]sensorValue, error) {
+	if err := j.leases.check(c, d.keys...); err != nil {
+		return nil, err
+	}
+	defer j.locks.lock(d.keys...)()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.sense()
+}
+
+// /api/periph/v1/devices/<name>/draw
+
+// drawIn is an image to draw on a display.
+type drawIn struct {
+	// Image is a PNG, GIF or JPEG encoded image. It is drawn at the top left
+	// of the display without scaling. It must not be larger than the display.
+	Image []byte
+}
+
+type drawOut struct {
+	Err string
+}
+
+// apiDeviceDraw draws on the display d. It returns 409 if its bus is leased
+// by another client.
+func (j *jsonAPI) apiDeviceDraw(c *caller, d *device, in *drawIn) (*drawOut, int) {
+	// Check the size before decoding, so a small file can't make the server
+	// allocate a huge image.
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(in.Image))
+	if err != nil {
+		return &drawOut{Err: err.Error()}, 200
+	}
+	r := d.drawer.Bounds()
+	if cfg.Width > r.Dx() || cfg.Height > r.Dy() {
+		return &drawOut{Err: fmt.Sprintf("image is %dx%d; larger than the display %dx%d", cfg.Width, cfg.Height, r.Dx(), r.Dy())}, 200
+	}
+	img, _, err := image.Decode(bytes.NewReader(in.Image))
+	if err != nil {
+		return &drawOut{Err: err.Error()}, 200
+	}
+	if err = j.leases.check(c, d.keys...); err != nil {
+		return &drawOut{Err: err.Error()}, 409
+	}
+	defer j.locks.lock(d.keys...)()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err = d.drawer.Draw(r, img, img.Bounds().Min); err != nil {
+		return &drawOut{Err: err.Error()}, 200
+	}
+	return &drawOut{}, 200
+}
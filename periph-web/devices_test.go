@@ -0,0 +1,166 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"periph.io/x/conn/v3/display/displaytest"
+)
+
+// TestDecodeYAML verifies that the YAML and JSON configurations decode the
+// same way.
+func TestDecodeYAML(t *testing.T) {
+	want := devicesConfig{Devices: []deviceConfig{
+		{Name: "env", Type: "bmxx80", I2C: "1", Addr: 0x76},
+		{Name: "oled", Type: "ssd1306", SPI: "SPI0.0", DC: "GPIO25", W: 128, H: 64},
+	}}
+	for _, raw := range []string{
+		"Devices:\n  - {Name: env, Type: bmxx80, I2C: \"1\", Addr: 0x76}\n  - Name: oled\n    Type: ssd1306\n    SPI: SPI0.0\n    DC: GPIO25\n    W: 128\n    H: 64\n",
+		`{"Devices": [{"Name": "env", "Type": "bmxx80", "I2C": "1", "Addr": 118}, {"Name": "oled", "Type": "ssd1306", "SPI": "SPI0.0", "DC": "GPIO25", "W": 128, "H": 64}]}`,
+	} {
+		var got devicesConfig
+		if err := decodeYAML([]byte(raw), &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%+v", got)
+		}
+	}
+	for _, raw := range []string{
+		"Devices:\n  - {Name: env, Bus: 1}\n",
+		"Devices: {Name: env}\n",
+		"Devices: [\n",
+	} {
+		if err := decodeYAML([]byte(raw), &devicesConfig{}); err == nil {
+			t.Fatalf("%q: expected error", raw)
+		}
+	}
+}
+
+// TestLoadDevicesInvalid verifies that the invalid devices are rejected
+// instead of being silently misconfigured or making the server panic.
+func TestLoadDevicesInvalid(t *testing.T) {
+	registerFakes(t)
+	for _, c := range []struct {
+		cfg  deviceConfig
+		want string
+	}{
+		// The names that can't be used in a URL pattern.
+		{deviceConfig{Name: "", Type: "bmxx80", I2C: "WEBI2C"}, "Name"},
+		{deviceConfig{Name: "my sensor", Type: "bmxx80", I2C: "WEBI2C"}, "Name"},
+		{deviceConfig{Name: "a{b}", Type: "bmxx80", I2C: "WEBI2C"}, "Name"},
+		{deviceConfig{Name: "a/b", Type: "bmxx80", I2C: "WEBI2C"}, "Name"},
+		{deviceConfig{Name: "a", Type: "bmxx80"}, "exactly one of I2C or SPI"},
+		{deviceConfig{Name: "a", Type: "bmxx80", SPI: "WEBSPI", Addr: 0x77}, "Addr requires I2C"},
+		{deviceConfig{Name: "a", Type: "ssd1306", I2C: "WEBI2C", Addr: 0x3D}, "only supports Addr 0x3C"},
+		{deviceConfig{Name: "a", Type: "bmxx80", I2C: "NOPE"}, "not found"},
+		{deviceConfig{Name: "a", Type: "nope", I2C: "WEBI2C"}, "unsupported Type"},
+	} {
+		p := filepath.Join(t.TempDir(), "devices.yaml")
+		raw, _ := json.Marshal(devicesConfig{Devices: []deviceConfig{c.cfg}})
+		if err := os.WriteFile(p, raw, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadDevices(p); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%+v: %v", c.cfg, err)
+		}
+	}
+}
+
+// TestWebDevices verifies the device endpoints honor the leases and the
+// display size.
+func TestWebDevices(t *testing.T) {
+	registerFakes(t)
+	reads := 0
+	drawer := &displaytest.Drawer{Img: image.NewNRGBA(image.Rect(0, 0, 4, 2))}
+	devs := []*device{
+		{
+			cfg:  deviceConfig{Name: "env", Type: "bmxx80", I2C: "WEBI2C"},
+			keys: []string{"i2c:WEBI2C"},
+			sense: func() ([]sensorValue, error) {
+				reads++
+				return []sensorValue{{"temperature", 21.5, "celsius", "21.500°C"}}, nil
+			},
+		},
+		{
+			cfg:    deviceConfig{Name: "oled", Type: "ssd1306", SPI: "WEBSPI"},
+			keys:   []string{"spi:WEBSPI"},
+			drawer: drawer,
+		},
+	}
+	ts := newTestServer(t, &webOpts{devices: devs})
+
+	var list []deviceRef
+	ts.post(t, "/api/periph/v1/devices/list", map[string]string{}, &list, 200)
+	if !reflect.DeepEqual(list, []deviceRef{{"env", "bmxx80", true, false}, {"oled", "ssd1306", false, true}}) {
+		t.Fatal(list)
+	}
+
+	var s senseOut
+	ts.post(t, "/api/periph/v1/devices/env/sense", map[string]string{}, &s, 200)
+	if s.Err != "" || len(s.Values) != 1 || s.Values[0].Value != 21.5 || reads != 1 {
+		t.Fatal(s, reads)
+	}
+
+	var d drawOut
+	ts.post(t, "/api/periph/v1/devices/oled/draw", nil, nil, 400)
+	ts.post(t, "/api/periph/v1/devices/oled/draw", &drawIn{Image: encodePNG(t, 4, 2)}, &d, 200)
+	if d.Err != "" || drawer.Img.NRGBAAt(3, 1) != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatal(d)
+	}
+	// The size is checked before decoding the image.
+	ts.post(t, "/api/periph/v1/devices/oled/draw", &drawIn{Image: encodePNG(t, 5, 2)}, &d, 200)
+	if d.Err != "image is 5x2; larger than the display 4x2" {
+		t.Fatal(d)
+	}
+	ts.post(t, "/api/periph/v1/devices/oled/draw", &drawIn{Image: []byte("nope")}, &d, 200)
+	if d.Err == "" {
+		t.Fatal("expected error")
+	}
+	ts.post(t, "/api/periph/v1/devices/oled/draw", &drawIn{Image: make([]byte, maxRequestSize)}, nil, 413)
+
+	// The buses leased by another client can't be used.
+	var l leaseOut
+	ts.post(t, "/api/periph/v1/lease/acquire", &leaseIn{I2C: []string{"99"}, SPI: []string{"WEBSPI"}}, &l, 200)
+	ts.post(t, "/api/periph/v1/devices/env/sense", map[string]string{}, &s, 409)
+	ts.post(t, "/api/periph/v1/devices/oled/draw", &drawIn{Image: encodePNG(t, 4, 2)}, &d, 409)
+	if reads != 1 {
+		t.Fatal("the sensor was read", reads)
+	}
+	// Nor sampled in the background.
+	if _, err := ts.s.apis.senseDevice(&caller{}, devs[0]); err == nil || reads != 1 {
+		t.Fatal(err, reads)
+	}
+	hdr := http.Header{leaseHeader: {l.ID}}
+	ts.postWith(t, hdr, "/api/periph/v1/devices/env/sense", map[string]string{}, &s, 200)
+	ts.postWith(t, hdr, "/api/periph/v1/devices/oled/draw", &drawIn{Image: encodePNG(t, 4, 2)}, &d, 200)
+	if reads != 2 || d.Err != "" {
+		t.Fatal(reads, d)
+	}
+}
+
+// encodePNG returns a white w x h PNG image.
+func encodePNG(t *testing.T, w, h int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
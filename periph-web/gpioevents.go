@@ -0,0 +1,212 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+)
+
+// edgePollTimeout is the maximum time a watcher blocks in WaitForEdge() before
+// checking if it was asked to stop. Edges are latched by the driver so no edge
+// is lost when the timeout expires.
+const edgePollTimeout = 100 * time.Millisecond
+
+// keepAliveInterval is the interval at which a comment is sent on idle event
+// streams so proxies do not close the connection.
+const keepAliveInterval = 30 * time.Second
+
+// gpioEdge is an edge detected on a GPIO.
+type gpioEdge struct {
+	Name  string
+	Level bool
+	Time  time.Time
+}
+
+// streamEvent is a Server-Sent Event.
+type streamEvent struct {
+	name string
+	data []byte
+}
+
+// edgeWatcher is a goroutine calling WaitForEdge() in a loop on a pin.
+type edgeWatcher struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// gpioEvents watches GPIOs configured with edge detection and fans out the
+// edges to all the subscribers.
+type gpioEvents struct {
+	mu       sync.Mutex
+	watchers map[string]*edgeWatcher
+	subs     map[chan streamEvent]struct{}
+	closed   bool
+}
+
+// watch starts watching edges on p. It must be called after p.In() with an
+// edge.
+func (g *gpioEvents) watch(p gpio.PinIO) {
+	g.unwatch(p.Name())
+	w := &edgeWatcher{stop: make(chan struct{}), done: make(chan struct{})}
+	g.mu.Lock()
+	if g.watchers == nil {
+		g.watchers = map[string]*edgeWatcher{}
+	}
+	g.watchers[p.Name()] = w
+	g.broadcastLocked(g.watchEventLocked())
+	g.mu.Unlock()
+	go g.run(p, w)
+}
+
+// unwatch stops watching edges on the pin named name, if it was.
+//
+// It must be called before reconfiguring the pin, so WaitForEdge() is not
+// called concurrently.
+func (g *gpioEvents) unwatch(name string) {
+	g.mu.Lock()
+	w := g.watchers[name]
+	if w != nil {
+		delete(g.watchers, name)
+		g.broadcastLocked(g.watchEventLocked())
+	}
+	g.mu.Unlock()
+	if w != nil {
+		close(w.stop)
+		<-w.done
+	}
+}
+
+func (g *gpioEvents) run(p gpio.PinIO, w *edgeWatcher) {
+	defer close(w.done)
+	for {
+		select {
+		case <-w.stop:
+			return
+		default:
+		}
+		if p.WaitForEdge(edgePollTimeout) {
+			e := gpioEdge{Name: p.Name(), Level: bool(p.Read()), Time: time.Now()}
+			raw, _ := json.Marshal(e)
+			g.mu.Lock()
+			g.broadcastLocked(streamEvent{"edge", raw})
+			g.mu.Unlock()
+		}
+	}
+}
+
+// subscribe returns a channel that receives the events. The first event is
+// the list of watched pins.
+func (g *gpioEvents) subscribe() chan streamEvent {
+	c := make(chan streamEvent, 64)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		close(c)
+		return c
+	}
+	if g.subs == nil {
+		g.subs = map[chan streamEvent]struct{}{}
+	}
+	g.subs[c] = struct{}{}
+	c <- g.watchEventLocked()
+	return c
+}
+
+func (g *gpioEvents) unsubscribe(c chan streamEvent) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.subs[c]; ok {
+		delete(g.subs, c)
+		close(c)
+	}
+}
+
+// close stops all the watchers and disconnects all the subscribers.
+func (g *gpioEvents) close() {
+	g.mu.Lock()
+	g.closed = true
+	for c := range g.subs {
+		close(c)
+	}
+	g.subs = nil
+	names := make([]string, 0, len(g.watchers))
+	for name := range g.watchers {
+		names = append(names, name)
+	}
+	g.mu.Unlock()
+	for _, name := range names {
+		g.unwatch(name)
+	}
+}
+
+func (g *gpioEvents) watchEventLocked() streamEvent {
+	names := make([]string, 0, len(g.watchers))
+	for name := range g.watchers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	raw, _ := json.Marshal(names)
+	return streamEvent{"watch", raw}
+}
+
+// broadcastLocked sends the event to every subscriber. A subscriber that is
+// too slow to keep up misses events instead of blocking the watchers.
+func (g *gpioEvents) broadcastLocked(e streamEvent) {
+	for c := range g.subs {
+		select {
+		case c <- e:
+		default:
+			log.Printf("Dropping %s event for slow subscriber", e.name)
+		}
+	}
+}
+
+// /raw/periph/v1/gpio/events
+
+// getGPIOEvents streams GPIO edges as Server-Sent Events.
+//
+// A "watch" event contains the list of pins being watched, it is sent upon
+// connection and every time the list changes. An "edge" event contains a
+// gpioEdge.
+func (s *webServer) getGPIOEvents(w http.ResponseWriter, r *http.Request) {
+	f, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", 500)
+		return
+	}
+	c := s.apis.events.subscribe()
+	defer s.apis.events.unsubscribe(c)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", cacheControlNone)
+	w.WriteHeader(200)
+	t := time.NewTicker(keepAliveInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-t.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-c:
+			if !ok {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.name, e.data); err != nil {
+				return
+			}
+		}
+		f.Flush()
+	}
+}
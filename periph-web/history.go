@@ -0,0 +1,357 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/conn/v3/pin"
+)
+
+// historySaveInterval is the interval at which the history is written to the
+// -history-file, so a crash loses little.
+const historySaveInterval = 5 * time.Minute
+
+// historyOpts are the settings of the sampler.
+type historyOpts struct {
+	// interval is the sampling interval.
+	interval time.Duration
+	// size is the number of samples kept per series.
+	size int
+	// file, when set, is where the history is persisted across restarts.
+	file string
+}
+
+// ring is a fixed size circular buffer of samples.
+type ring struct {
+	unit string
+	// t is the time of the samples in milliseconds since the epoch.
+	t []int64
+	v []float64
+	// next is where the next sample goes, which is the oldest sample once the
+	// buffer is full.
+	next int
+	full bool
+}
+
+func newRing(size int, unit string) *ring {
+	return &ring{unit: unit, t: make([]int64, size), v: make([]float64, size)}
+}
+
+func (r *ring) add(t int64, v float64) {
+	r.t[r.next] = t
+	r.v[r.next] = v
+	if r.next++; r.next == len(r.t) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// since returns the samples strictly after t in chronological order.
+func (r *ring) since(t int64) ([]int64, []float64) {
+	ts := []int64{}
+	vs := []float64{}
+	start, n := 0, r.next
+	if r.full {
+		start, n = r.next, len(r.t)
+	}
+	for i := 0; i < n; i++ {
+		j := (start + i) % len(r.t)
+		if r.t[j] > t {
+			ts = append(ts, r.t[j])
+			vs = append(vs, r.v[j])
+		}
+	}
+	return ts, vs
+}
+
+// historySeries is the samples of a series, in columns to keep the JSON
+// small.
+type historySeries struct {
+	// Name is "gpio/<pin>" or "sensor/<device>/<quantity>".
+	Name string
+	Unit string
+	// T is the time of each sample in milliseconds since the epoch.
+	T []int64
+	V []float64
+}
+
+// history samples the GPIOs levels and the sensors measurements in the
+// background.
+type history struct {
+	opts historyOpts
+	j    *jsonAPI
+	stop chan struct{}
+	done chan struct{}
+
+	mu     sync.Mutex
+	series map[string]*ring
+	// sensed is the last successful reading of each device, by name.
+	sensed map[string][]sensorValue
+}
+
+// newHistory loads the history from opts.file, if any, and starts sampling.
+func newHistory(j *jsonAPI, opts *historyOpts) (*history, error) {
+	h := &history{
+		opts:   *opts,
+		j:      j,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		series: map[string]*ring{},
+		sensed: map[string][]sensorValue{},
+	}
+	if h.opts.file != "" {
+		if err := h.load(); err != nil {
+			return nil, err
+		}
+	}
+	go h.run()
+	return h, nil
+}
+
+// close stops sampling and saves the history.
+func (h *history) close() error {
+	close(h.stop)
+	<-h.done
+	if h.opts.file == "" {
+		return nil
+	}
+	return h.save()
+}
+
+func (h *history) run() {
+	defer close(h.done)
+	h.sample(time.Now())
+	tick := time.NewTicker(h.opts.interval)
+	defer tick.Stop()
+	lastSave := time.Now()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case now := <-tick.C:
+			h.sample(now)
+			if h.opts.file != "" && now.Sub(lastSave) >= historySaveInterval {
+				if err := h.save(); err != nil {
+					log.Printf("history: %v", err)
+				}
+				lastSave = now
+			}
+		}
+	}
+}
+
+// sample records the level of the GPIOs set as input or output and the
+// measurements of the sensors in the -devices configuration file.
+//
+// NaN and infinite measurements are skipped since they can't be encoded in
+// JSON.
+func (h *history) sample(now time.Time) {
+	t := now.UnixNano() / int64(time.Millisecond)
+	for _, p := range gpioreg.All() {
+		if pinDirection(p) == pin.FuncNone {
+			continue
+		}
+		v := 0.
+		if p.Read() {
+			v = 1
+		}
+		h.add("gpio/"+p.Name(), "", t, v)
+	}
+	for _, d := range h.j.devices {
+		if d.sense == nil {
+			continue
+		}
+		values, err := h.j.senseDevice(&caller{}, d)
+		if err != nil {
+			log.Printf("history: %s: %v", d.cfg.Name, err)
+			continue
+		}
+		var finite []sensorValue
+		for _, v := range values {
+			if !math.IsNaN(v.Value) && !math.IsInf(v.Value, 0) {
+				finite = append(finite, v)
+			}
+		}
+		h.mu.Lock()
+		h.sensed[d.cfg.Name] = finite
+		h.mu.Unlock()
+		for _, v := range finite {
+			h.add("sensor/"+d.cfg.Name+"/"+v.Quantity, v.Unit, t, v.Value)
+		}
+	}
+}
+
+// lastSensed returns the values of the last successful reading of the device
+// name, if any.
+func (h *history) lastSensed(name string) []sensorValue {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sensed[name]
+}
+
+func (h *history) add(name, unit string, t int64, v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r := h.series[name]
+	if r == nil {
+		r = newRing(h.opts.size, unit)
+		h.series[name] = r
+	}
+	r.add(t, v)
+}
+
+// query returns the samples after since of the series names, or of all the
+// series when names is empty.
+func (h *history) query(names []string, since int64) []historySeries {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(names) == 0 {
+		for name := range h.series {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	out := make([]historySeries, 0, len(names))
+	for _, name := range names {
+		s := historySeries{Name: name, T: []int64{}, V: []float64{}}
+		if r := h.series[name]; r != nil {
+			s.Unit = r.unit
+			s.T, s.V = r.since(since)
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// historyFile is the content of the -history-file.
+type historyFile struct {
+	Series []historySeries
+}
+
+// load reads the history saved by a previous process. A missing file is not
+// an error.
+func (h *history) load() error {
+	raw, err := os.ReadFile(h.opts.file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var f historyFile
+	if err = json.Unmarshal(raw, &f); err != nil {
+		return fmt.Errorf("%s: %v", h.opts.file, err)
+	}
+	for _, s := range f.Series {
+		if len(s.T) != len(s.V) {
+			return fmt.Errorf("%s: %s: mismatched T and V", h.opts.file, s.Name)
+		}
+		// Only keep the most recent samples when -history-size was reduced.
+		for i := range s.T {
+			h.add(s.Name, s.Unit, s.T[i], s.V[i])
+		}
+	}
+	return nil
+}
+
+// save atomically writes the history to the file.
+func (h *history) save() error {
+	raw, err := json.Marshal(historyFile{Series: h.query(nil, 0)})
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(h.opts.file), filepath.Base(h.opts.file)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(raw); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err = f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), h.opts.file)
+}
+
+// parseSince parses the since query argument: milliseconds since the epoch, a
+// RFC 3339 timestamp or a duration before now, e.g. "1h".
+func parseSince(s string, now time.Time) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return ms, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d).UnixNano() / int64(time.Millisecond), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, errors.New("invalid since; use milliseconds since the epoch, RFC 3339 or a duration")
+	}
+	return t.UnixNano() / int64(time.Millisecond), nil
+}
+
+// /api/periph/v1/history
+
+type historyOut struct {
+	// Interval is the sampling interval in milliseconds.
+	Interval int64
+	Series   []historySeries
+	Err      string
+}
+
+// getHistory returns the samples recorded since the "since" query argument of
+// the series listed in "series" query arguments, comma separated or repeated.
+// All the series are returned when "series" is not specified.
+func (s *webServer) getHistory(w http.ResponseWriter, r *http.Request) {
+	var out historyOut
+	h := s.apis.history
+	if h == nil {
+		out.Err = "history is disabled; use -history"
+	} else {
+		q := r.URL.Query()
+		since, err := parseSince(q.Get("since"), time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var names []string
+		for _, v := range q["series"] {
+			for _, n := range strings.Split(v, ",") {
+				if n != "" {
+					names = append(names, n)
+				}
+			}
+		}
+		out.Interval = int64(h.opts.interval / time.Millisecond)
+		out.Series = h.query(names, since)
+	}
+	raw, err := json.Marshal(&out)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", cacheControlNone)
+	_, _ = w.Write(raw)
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"periph.io/x/conn/v3/driver/driverreg"
+)
+
+func TestRing(t *testing.T) {
+	r := newRing(3, "volt")
+	if ts, vs := r.since(0); len(ts) != 0 || len(vs) != 0 {
+		t.Fatal(ts, vs)
+	}
+	r.add(1, 10)
+	r.add(2, 20)
+	if ts, vs := r.since(0); !reflect.DeepEqual(ts, []int64{1, 2}) || !reflect.DeepEqual(vs, []float64{10, 20}) {
+		t.Fatal(ts, vs)
+	}
+	// Wrap around; the oldest samples are dropped.
+	for i := int64(3); i <= 7; i++ {
+		r.add(i, float64(i*10))
+	}
+	if ts, vs := r.since(0); !reflect.DeepEqual(ts, []int64{5, 6, 7}) || !reflect.DeepEqual(vs, []float64{50, 60, 70}) {
+		t.Fatal(ts, vs)
+	}
+	// since is exclusive.
+	if ts, vs := r.since(6); !reflect.DeepEqual(ts, []int64{7}) || !reflect.DeepEqual(vs, []float64{70}) {
+		t.Fatal(ts, vs)
+	}
+	if ts, _ := r.since(7); len(ts) != 0 {
+		t.Fatal(ts)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	ms := now.UnixNano() / int64(time.Millisecond)
+	for _, c := range []struct {
+		in   string
+		want int64
+	}{
+		{"", 0},
+		{"1234", 1234},
+		{"1h", ms - 3600000},
+		{"1500ms", ms - 1500},
+		{"2026-01-02T03:04:05Z", ms},
+		{"2026-01-02T04:04:05+01:00", ms},
+	} {
+		if got, err := parseSince(c.in, now); err != nil || got != c.want {
+			t.Errorf("%q: %d, %v", c.in, got, err)
+		}
+	}
+	for _, in := range []string{"yesterday", "2026-01-02", "1.5"} {
+		if _, err := parseSince(in, now); err == nil {
+			t.Errorf("%q: expected error", in)
+		}
+	}
+}
+
+// TestHistoryQuery verifies the series selection and the since filtering.
+func TestHistoryQuery(t *testing.T) {
+	h := &history{opts: historyOpts{size: 4}, series: map[string]*ring{}}
+	h.add("sensor/env/temperature", "celsius", 1, 20)
+	h.add("sensor/env/temperature", "celsius", 2, 21)
+	h.add("gpio/GPIO1", "", 2, 1)
+	want := []historySeries{
+		{Name: "gpio/GPIO1", T: []int64{2}, V: []float64{1}},
+		{Name: "sensor/env/temperature", Unit: "celsius", T: []int64{1, 2}, V: []float64{20, 21}},
+	}
+	if got := h.query(nil, 0); !reflect.DeepEqual(got, want) {
+		t.Fatal(got)
+	}
+	want = []historySeries{
+		{Name: "sensor/env/temperature", Unit: "celsius", T: []int64{2}, V: []float64{21}},
+		{Name: "nope", T: []int64{}, V: []float64{}},
+	}
+	if got := h.query([]string{"sensor/env/temperature", "nope"}, 1); !reflect.DeepEqual(got, want) {
+		t.Fatal(got)
+	}
+}
+
+func TestHistorySaveLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history.json")
+	h := &history{opts: historyOpts{size: 4, file: file}, series: map[string]*ring{}}
+	// A missing file is not an error.
+	if err := h.load(); err != nil {
+		t.Fatal(err)
+	}
+	for i := int64(1); i <= 6; i++ {
+		h.add("sensor/env/temperature", "celsius", i, float64(20+i))
+	}
+	h.add("gpio/GPIO1", "", 6, 1)
+	if err := h.save(); err != nil {
+		t.Fatal(err)
+	}
+	h2 := &history{opts: historyOpts{size: 4, file: file}, series: map[string]*ring{}}
+	if err := h2.load(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := h2.query(nil, 0), h.query(nil, 0); !reflect.DeepEqual(got, want) {
+		t.Fatal(got, want)
+	}
+	// Only the most recent samples are kept when the size was reduced.
+	h3 := &history{opts: historyOpts{size: 2, file: file}, series: map[string]*ring{}}
+	if err := h3.load(); err != nil {
+		t.Fatal(err)
+	}
+	if got := h3.query([]string{"sensor/env/temperature"}, 0); !reflect.DeepEqual(got[0].T, []int64{5, 6}) {
+		t.Fatal(got)
+	}
+}
+
+// TestHistorySampleNonFinite verifies that a sensor returning NaN doesn't
+// break the JSON encoding of the history.
+func TestHistorySampleNonFinite(t *testing.T) {
+	var j jsonAPI
+	j.init("localhost", &driverreg.State{})
+	j.devices = []*device{{
+		cfg: deviceConfig{Name: "env"},
+		sense: func() ([]sensorValue, error) {
+			return []sensorValue{
+				{Quantity: "temperature", Value: 21.5, Unit: "celsius"},
+				{Quantity: "humidity", Value: math.NaN(), Unit: "percent"},
+				{Quantity: "pressure", Value: math.Inf(1), Unit: "pascal"},
+			}, nil
+		},
+	}}
+	h := &history{j: &j, opts: historyOpts{size: 2}, series: map[string]*ring{}, sensed: map[string][]sensorValue{}}
+	h.sample(time.Now())
+	got := h.query(nil, 0)
+	if len(got) != 1 || got[0].Name != "sensor/env/temperature" {
+		t.Fatal(got)
+	}
+	if _, err := json.Marshal(historyFile{Series: got}); err != nil {
+		t.Fatal(err)
+	}
+	if v := h.lastSensed("env"); len(v) != 1 {
+		t.Fatal(v)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/i2c/i2creg"
+)
+
+// /api/periph/v1/i2c/scan
+
+// The 7-bit addresses probed, skipping the ones reserved by the I²C
+// specification.
+const (
+	i2cScanFirst = 0x08
+	i2cScanLast  = 0x77
+)
+
+// i2cScanIn is a request to scan an I²C bus.
+type i2cScanIn struct {
+	// Bus is the name, alias or number of the bus.
+	Bus string
+	// Mode is the probing method:
+	//   - "quick": SMBus quick write, only supported on linux for the buses
+	//     of the sysfs driver. Reading could confuse write-only devices.
+	//   - "read": read one byte. Quick write could corrupt EEPROMs.
+	//   - "auto" or empty: the same as i2cdetect, read one byte for
+	//     0x30~0x37 and 0x50~0x5F and quick write elsewhere, falling back to
+	//     read where quick write is not supported.
+	Mode string
+}
+
+// i2cScanned is a device that acknowledged its address.
+type i2cScanned struct {
+	Addr uint16
+	// Busy is true when the address is in use by a kernel driver and was not
+	// probed.
+	Busy bool
+	// Devices are the periph devices commonly found at this address.
+	Devices []string
+}
+
+type i2cScanOut struct {
+	Mode  string
+	Found []i2cScanned
+	Err   string
+}
+
+// i2cProber probes an address without transferring data, which i2c.Bus
+// doesn't support.
+type i2cProber interface {
+	probe(addr uint16) (found, busy bool)
+	Close() error
+}
+
+// apiI2CScan probes every address on a bus. It returns 400 if the bus is not
+// found and 409 if it is leased by another client.
+func (j *jsonAPI) apiI2CScan(c *caller, in *i2cScanIn) (*i2cScanOut, int) {
+	k, err := i2cKey(in.Bus)
+	if err != nil {
+		return &i2cScanOut{Err: err.Error()}, 400
+	}
+	if err = j.leases.check(c, k); err != nil {
+		return &i2cScanOut{Err: err.Error()}, 409
+	}
+	defer j.locks.lock(k)()
+	out := &i2cScanOut{Mode: in.Mode}
+	if out.Mode == "" {
+		out.Mode = "auto"
+	}
+	if out.Mode != "auto" && out.Mode != "quick" && out.Mode != "read" {
+		out.Err = "invalid mode"
+		return out, 200
+	}
+	bus, err := i2creg.Open(in.Bus)
+	if err != nil {
+		out.Err = err.Error()
+		return out, 200
+	}
+	defer bus.Close()
+	var q i2cProber
+	if out.Mode != "read" {
+		if q, err = openI2CProber(bus, i2cNumber(k)); err != nil {
+			if out.Mode == "quick" {
+				out.Err = err.Error()
+				return out, 200
+			}
+			out.Mode = "read"
+		} else {
+			defer q.Close()
+		}
+	}
+	out.Found = []i2cScanned{}
+	for addr := uint16(i2cScanFirst); addr <= i2cScanLast; addr++ {
+		var found, busy bool
+		if q != nil && (out.Mode == "quick" || !i2cReadProbe(addr)) {
+			found, busy = q.probe(addr)
+		} else {
+			found = readProbe(bus, addr)
+		}
+		if found {
+			out.Found = append(out.Found, i2cScanned{Addr: addr, Busy: busy, Devices: i2cDevicesAt(addr)})
+		}
+	}
+	return out, 200
+}
+
+// i2cNumber returns the number of the bus with the resource key k, or -1.
+func i2cNumber(k string) int {
+	for _, ref := range i2creg.All() {
+		if "i2c:"+ref.Name == k {
+			return ref.Number
+		}
+	}
+	return -1
+}
+
+// i2cReadProbe returns true if i2cdetect probes addr by reading in auto mode.
+// These ranges are used by EEPROMs, which a quick write could corrupt.
+func i2cReadProbe(addr uint16) bool {
+	return addr >= 0x30 && addr <= 0x37 || addr >= 0x50 && addr <= 0x5F
+}
+
+// readProbe returns true if a device acknowledged a one byte read at addr.
+func readProbe(bus i2c.Bus, addr uint16) bool {
+	var b [1]byte
+	return bus.Tx(addr, nil, b[:]) == nil
+}
+
+// i2cDevices are periph device drivers and the addresses they can be
+// configured to use.
+var i2cDevices = []struct {
+	name        string
+	first, last uint16
+}{
+	{"ads1x15", 0x48, 0x4B},
+	{"adxl345", 0x1D, 0x1D},
+	{"adxl345", 0x53, 0x53},
+	{"aht20", 0x38, 0x38},
+	{"am2320", 0x5C, 0x5C},
+	{"bh1750", 0x23, 0x23},
+	{"bh1750", 0x5C, 0x5C},
+	{"bmxx80", 0x76, 0x77},
+	{"cap1xxx", 0x28, 0x2D},
+	{"ccs811", 0x5A, 0x5B},
+	{"ds248x", 0x18, 0x1B},
+	{"hdc302x", 0x44, 0x47},
+	{"ht16k33", 0x70, 0x77},
+	{"ina219", 0x40, 0x4F},
+	{"mcp23xxx", 0x20, 0x27},
+	{"mcp9808", 0x18, 0x1F},
+	{"mpu9250", 0x68, 0x69},
+	{"pca9548", 0x70, 0x77},
+	{"pca9685", 0x40, 0x77},
+	{"pcf857x", 0x20, 0x27},
+	{"pcf857x", 0x38, 0x3F},
+	{"scd4x", 0x62, 0x62},
+	{"sgp30", 0x58, 0x58},
+	{"ssd1306", 0x3C, 0x3D},
+	{"tca95xx", 0x20, 0x27},
+	{"tlv493d", 0x1F, 0x1F},
+	{"tlv493d", 0x5E, 0x5E},
+	{"tmp102", 0x48, 0x4B},
+}
+
+// i2cDevicesAt returns the periph devices that can be at addr.
+func i2cDevicesAt(addr uint16) []string {
+	out := []string{}
+	for _, d := range i2cDevices {
+		if addr >= d.first && addr <= d.last {
+			out = append(out, d.name)
+		}
+	}
+	return out
+}
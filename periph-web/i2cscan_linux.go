@@ -0,0 +1,77 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"syscall"
+	"unsafe"
+
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/host/v3/sysfs"
+)
+
+// From <linux/i2c-dev.h> and <linux/i2c.h>.
+const (
+	ioctlI2CSlave = 0x0703
+	ioctlI2CSMBus = 0x0720
+	i2cSMBusWrite = 0
+	i2cSMBusQuick = 0
+	i2cDevicePath = "/dev/i2c-"
+)
+
+// i2cSMBusIoctlData is struct i2c_smbus_ioctl_data.
+type i2cSMBusIoctlData struct {
+	readWrite uint8
+	command   uint8
+	size      uint32
+	data      uintptr
+}
+
+// i2cQuickProber probes with a SMBus quick write through /dev/i2c-N.
+type i2cQuickProber struct {
+	f *os.File
+}
+
+// openI2CProber opens the bus number for quick write probing.
+//
+// bus is the bus opened by i2creg. /dev/i2c-<number> is only opened when bus
+// is the sysfs bus with this number, so the prober can't access another bus
+// than bus, e.g. when another driver registered a bus with the same number.
+func openI2CProber(bus i2c.Bus, number int) (i2cProber, error) {
+	if s, ok := bus.(*sysfs.I2C); !ok || number < 0 || s.String() != "I2C"+strconv.Itoa(number) {
+		return nil, errors.New("quick write requires a sysfs bus")
+	}
+	f, err := os.OpenFile(i2cDevicePath+strconv.Itoa(number), os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &i2cQuickProber{f: f}, nil
+}
+
+func (q *i2cQuickProber) probe(addr uint16) (bool, bool) {
+	// I2C_SLAVE fails with EBUSY when a kernel driver is bound to the address;
+	// i2cdetect shows it as "UU".
+	if err := q.ioctl(ioctlI2CSlave, uintptr(addr)); err != nil {
+		return err == syscall.EBUSY, err == syscall.EBUSY
+	}
+	d := i2cSMBusIoctlData{readWrite: i2cSMBusWrite, size: i2cSMBusQuick}
+	// The pointer conversion must be in the Syscall call expression.
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, q.f.Fd(), ioctlI2CSMBus, uintptr(unsafe.Pointer(&d)))
+	return errno == 0, false
+}
+
+func (q *i2cQuickProber) Close() error {
+	return q.f.Close()
+}
+
+func (q *i2cQuickProber) ioctl(op, arg uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, q.f.Fd(), op, arg); errno != 0 {
+		return errno
+	}
+	return nil
+}
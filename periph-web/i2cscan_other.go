@@ -0,0 +1,19 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+//go:build !linux
+// +build !linux
+
+package main
+
+import (
+	"errors"
+
+	"periph.io/x/conn/v3/i2c"
+)
+
+// openI2CProber is only implemented on linux.
+func openI2CProber(bus i2c.Bus, number int) (i2cProber, error) {
+	return nil, errors.New("quick write is only supported on linux")
+}
@@ -5,11 +5,18 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
 	"periph.io/x/conn/v3/driver/driverreg"
 	"periph.io/x/conn/v3/gpio"
 	"periph.io/x/conn/v3/gpio/gpioreg"
 	"periph.io/x/conn/v3/i2c"
 	"periph.io/x/conn/v3/i2c/i2creg"
+	"periph.io/x/conn/v3/physic"
 	"periph.io/x/conn/v3/pin"
 	"periph.io/x/conn/v3/pin/pinreg"
 	"periph.io/x/conn/v3/spi"
@@ -20,6 +27,20 @@ import (
 type jsonAPI struct {
 	hostname string
 	state    drvState
+	events   gpioEvents
+	leases   leases
+	// pins are the GPIOs modified via the API, to restore them on shutdown.
+	pins pinTracker
+	// devices are the devices loaded from the -devices configuration file.
+	devices []*device
+	// history is the sampled values, when enabled with -history.
+	history *history
+	// audit records the calls changing the GPIOs, when enabled with -audit.
+	audit *auditLog
+	// sequence serializes the sequences so their steps don't interleave.
+	sequence sync.Mutex
+	// locks serializes the calls using the same pins or buses.
+	locks resourceLocks
 }
 
 func (j *jsonAPI) init(hostname string, st *driverreg.State) {
@@ -42,17 +63,43 @@ func (j *jsonAPI) init(hostname string, st *driverreg.State) {
 
 // getAPIs returns the JSON API handlers.
 func (j *jsonAPI) getAPIs() []apiHandler {
-	return []apiHandler{
+	out := []apiHandler{
+		{"/api/periph/v1/devices/list", j.apiDevicesList},
 		{"/api/periph/v1/gpio/aliases", j.apiGPIOAliases},
 		{"/api/periph/v1/gpio/in", j.apiGPIOIn},
 		{"/api/periph/v1/gpio/list", j.apiGPIOList},
 		{"/api/periph/v1/gpio/read", j.apiGPIORead},
 		{"/api/periph/v1/gpio/out", j.apiGPIOOut},
+		{"/api/periph/v1/gpio/pwm", j.apiGPIOPWM},
 		{"/api/periph/v1/header/list", j.apiHeaderList},
 		{"/api/periph/v1/i2c/list", j.apiI2CList},
+		{"/api/periph/v1/i2c/scan", j.apiI2CScan},
+		{"/api/periph/v1/i2c/tx", j.apiI2CTx},
+		{"/api/periph/v1/lease/acquire", j.apiLeaseAcquire},
+		{"/api/periph/v1/lease/list", j.apiLeaseList},
+		{"/api/periph/v1/lease/release", j.apiLeaseRelease},
+		{"/api/periph/v1/lease/renew", j.apiLeaseRenew},
+		{"/api/periph/v1/onewire/ds18b20/read", j.apiDS18B20Read},
+		{"/api/periph/v1/onewire/list", j.apiOneWireList},
+		{"/api/periph/v1/sequence", j.apiSequence},
 		{"/api/periph/v1/spi/list", j.apiSPIList},
+		{"/api/periph/v1/spi/tx", j.apiSPITx},
 		{"/api/periph/v1/server/state", j.apiServerState},
 	}
+	for _, d := range j.devices {
+		prefix := "/api/periph/v1/devices/" + d.cfg.Name
+		if d.sense != nil {
+			out = append(out, apiHandler{prefix + "/sense", func(c *caller) (*senseOut, int) {
+				return j.apiDeviceSense(c, d)
+			}})
+		}
+		if d.drawer != nil {
+			out = append(out, apiHandler{prefix + "/draw", func(c *caller, in *drawIn) (*drawOut, int) {
+				return j.apiDeviceDraw(c, d, in)
+			}})
+		}
+	}
+	return out
 }
 
 //
@@ -63,10 +110,65 @@ type gpioPin struct {
 	Num  int
 	// Mutable, the GPIO can change function over time.
 	Func string
+	// Funcs are the functions the pin supports, e.g. "IN", "OUT" and "PWM".
+	Funcs []string
+}
+
+// pinDirection returns gpio.IN or gpio.OUT when p is set as an input or an
+// output, pin.FuncNone otherwise.
+func pinDirection(p pin.Pin) pin.Func {
+	pf, ok := p.(pin.PinFunc)
+	if !ok {
+		return pin.FuncNone
+	}
+	switch pf.Func() {
+	case gpio.IN, gpio.IN_HIGH, gpio.IN_LOW:
+		return gpio.IN
+	case gpio.OUT, gpio.OUT_OC, gpio.OUT_HIGH, gpio.OUT_LOW:
+		return gpio.OUT
+	}
+	return pin.FuncNone
 }
 
 func toPin(p pin.Pin) gpioPin {
-	return gpioPin{p.Name(), p.Number(), p.Function()}
+	out := gpioPin{p.Name(), p.Number(), p.Function(), []string{}}
+	if f, ok := p.(pin.PinFunc); ok {
+		for _, s := range f.SupportedFuncs() {
+			out.Funcs = append(out.Funcs, string(s))
+		}
+	}
+	return out
+}
+
+// byteList is a []byte that is serialized as a JSON list of numbers instead of
+// a base64 encoded string, which is much easier to use from a web browser or
+// curl.
+type byteList []byte
+
+func (b byteList) MarshalJSON() ([]byte, error) {
+	if b == nil {
+		return []byte("null"), nil
+	}
+	l := make([]int, len(b))
+	for i, v := range b {
+		l[i] = int(v)
+	}
+	return json.Marshal(l)
+}
+
+func (b *byteList) UnmarshalJSON(data []byte) error {
+	var l []int
+	if err := json.Unmarshal(data, &l); err != nil {
+		return err
+	}
+	*b = make(byteList, len(l))
+	for i, v := range l {
+		if v < 0 || v > 255 {
+			return fmt.Errorf("invalid byte value %d", v)
+		}
+		(*b)[i] = byte(v)
+	}
+	return nil
 }
 
 // /api/periph/v1/gpio/aliases
@@ -94,29 +196,20 @@ type pinIn struct {
 	Edge string
 }
 
-func (j *jsonAPI) apiGPIOIn(in []pinIn) ([]string, int) {
-	out := make([]string, 0, len(in))
+func (j *jsonAPI) apiGPIOIn(c *caller, in []pinIn) (out []string, status int) {
+	defer func() { j.audit.record(c, in, out, status) }()
+	names := make([]string, 0, len(in))
+	for _, l := range in {
+		names = append(names, l.Name)
+	}
+	if out, err := j.checkPins(c, names); err != nil {
+		return out, 409
+	}
+	defer j.lockPins(names)()
+	out = make([]string, 0, len(in))
 	for _, l := range in {
 		if p := gpioreg.ByName(l.Name); p != nil {
-			pull := gpio.PullNoChange
-			switch l.Pull {
-			case "down":
-				pull = gpio.PullDown
-			case "float":
-				pull = gpio.Float
-			case "up":
-				pull = gpio.PullUp
-			}
-			edge := gpio.NoEdge
-			switch l.Edge {
-			case "both":
-				edge = gpio.BothEdges
-			case "falling":
-				edge = gpio.FallingEdge
-			case "rising":
-				edge = gpio.RisingEdge
-			}
-			if err := p.In(pull, edge); err != nil {
+			if err := j.gpioIn(p, &l, true); err != nil {
 				out = append(out, err.Error())
 			} else {
 				out = append(out, "")
@@ -128,6 +221,38 @@ func (j *jsonAPI) apiGPIOIn(in []pinIn) ([]string, int) {
 	return out, 200
 }
 
+// gpioIn sets p as input. When watch is true and an edge is requested, the
+// edges are streamed to /raw/periph/v1/gpio/events.
+func (j *jsonAPI) gpioIn(p gpio.PinIO, l *pinIn, watch bool) error {
+	pull := gpio.PullNoChange
+	switch l.Pull {
+	case "down":
+		pull = gpio.PullDown
+	case "float":
+		pull = gpio.Float
+	case "up":
+		pull = gpio.PullUp
+	}
+	edge := gpio.NoEdge
+	switch l.Edge {
+	case "both":
+		edge = gpio.BothEdges
+	case "falling":
+		edge = gpio.FallingEdge
+	case "rising":
+		edge = gpio.RisingEdge
+	}
+	j.pins.touch(p)
+	j.events.unwatch(p.Name())
+	if err := p.In(pull, edge); err != nil {
+		return err
+	}
+	if watch && edge != gpio.NoEdge {
+		j.events.watch(p)
+	}
+	return nil
+}
+
 // /api/periph/v1/gpio/list
 
 func (j *jsonAPI) apiGPIOList() ([]gpioPin, int) {
@@ -157,11 +282,23 @@ func (j *jsonAPI) apiGPIORead(in []string) ([]int, int) {
 
 // /api/periph/v1/gpio/out
 
-func (j *jsonAPI) apiGPIOOut(in map[string]bool) ([]string, int) {
-	out := make([]string, 0, len(in))
-	for name, l := range in {
+// apiGPIOOut sets the pins in the order of their names, which is also the
+// order of the results. This is the order of the keys of in encoded in JSON.
+func (j *jsonAPI) apiGPIOOut(c *caller, in map[string]bool) (out []string, status int) {
+	defer func() { j.audit.record(c, in, out, status) }()
+	names := make([]string, 0, len(in))
+	for name := range in {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if out, err := j.checkPins(c, names); err != nil {
+		return out, 409
+	}
+	defer j.lockPins(names)()
+	out = make([]string, 0, len(in))
+	for _, name := range names {
 		if p := gpioreg.ByName(name); p != nil {
-			if err := p.Out(gpio.Level(l)); err != nil {
+			if err := j.gpioOut(p, gpio.Level(in[name])); err != nil {
 				out = append(out, err.Error())
 			} else {
 				out = append(out, "")
@@ -173,6 +310,97 @@ func (j *jsonAPI) apiGPIOOut(in map[string]bool) ([]string, int) {
 	return out, 200
 }
 
+// gpioOut sets p as output at level l.
+func (j *jsonAPI) gpioOut(p gpio.PinIO, l gpio.Level) error {
+	j.pins.touch(p)
+	j.events.unwatch(p.Name())
+	return p.Out(l)
+}
+
+// /api/periph/v1/gpio/pwm
+
+// pinPWM is a request to output PWM on a pin.
+//
+// Duty is parsed with gpio.ParseDuty, e.g. "25%", and Freq with
+// physic.Frequency.Set, e.g. "1kHz".
+type pinPWM struct {
+	Name string
+	Duty string
+	Freq string
+}
+
+// apiGPIOPWM starts PWM on the pins that support it.
+func (j *jsonAPI) apiGPIOPWM(c *caller, in []pinPWM) (out []string, status int) {
+	defer func() { j.audit.record(c, in, out, status) }()
+	names := make([]string, 0, len(in))
+	for _, l := range in {
+		names = append(names, l.Name)
+	}
+	if out, err := j.checkPins(c, names); err != nil {
+		return out, 409
+	}
+	defer j.lockPins(names)()
+	out = make([]string, 0, len(in))
+	for _, l := range in {
+		p := gpioreg.ByName(l.Name)
+		if p == nil {
+			out = append(out, "Pin not found")
+			continue
+		}
+		duty, err := gpio.ParseDuty(l.Duty)
+		if err != nil {
+			out = append(out, err.Error())
+			continue
+		}
+		var f physic.Frequency
+		if err = f.Set(l.Freq); err != nil {
+			out = append(out, err.Error())
+			continue
+		}
+		if f <= 0 {
+			out = append(out, "frequency must be positive")
+			continue
+		}
+		j.pins.touch(p)
+		j.events.unwatch(p.Name())
+		if err = p.PWM(duty, f); err != nil {
+			out = append(out, err.Error())
+		} else {
+			out = append(out, "")
+		}
+	}
+	return out, 200
+}
+
+// checkPins verifies that none of the pins is leased by another client than c.
+// On conflict, it returns one error per pin and none of the pins must be
+// modified.
+func (j *jsonAPI) checkPins(c *caller, names []string) ([]string, error) {
+	var err error
+	out := make([]string, len(names))
+	for i, name := range names {
+		out[i] = "Not modified due to conflict"
+		if p := gpioreg.ByName(name); p != nil {
+			if e := j.leases.check(c, pinKey(p)); e != nil {
+				err = e
+				out[i] = e.Error()
+			}
+		}
+	}
+	return out, err
+}
+
+// lockPins locks the pins found among names; see resourceLocks.
+func (j *jsonAPI) lockPins(names []string) func() {
+	var keys []string
+	for _, name := range names {
+		if p := gpioreg.ByName(name); p != nil {
+			keys = append(keys, pinKey(p))
+		}
+	}
+	return j.locks.lock(keys...)
+}
+
 // /api/periph/v1/header/list
 
 type header struct {
@@ -226,6 +454,82 @@ func (j *jsonAPI) apiI2CList() ([]i2cRef, int) {
 	return out, 200
 }
 
+// /api/periph/v1/i2c/tx
+
+// maxTxSize is the maximum number of bytes that can be read in a single bus
+// transaction.
+const maxTxSize = 4096
+
+// i2cTx is a single I²C transaction: write W then read R bytes from the device
+// at Addr on the bus Bus.
+type i2cTx struct {
+	Bus  string
+	Addr uint16
+	W    byteList
+	R    int
+}
+
+type i2cTxResult struct {
+	R   byteList
+	Err string
+}
+
+// apiI2CTx runs the transactions in order. An error in one transaction doesn't
+// prevent the next ones to be run.
+//
+// It returns 400 if one of the buses is not found and 409 if one is leased by
+// another client, without running any transaction.
+func (j *jsonAPI) apiI2CTx(c *caller, in []i2cTx) ([]i2cTxResult, int) {
+	keys := make([]string, 0, len(in))
+	for _, t := range in {
+		k, err := i2cKey(t.Bus)
+		if err != nil {
+			return []i2cTxResult{{Err: err.Error()}}, 400
+		}
+		if err = j.leases.check(c, k); err != nil {
+			return []i2cTxResult{{Err: err.Error()}}, 409
+		}
+		keys = append(keys, k)
+	}
+	defer j.locks.lock(keys...)()
+	buses := map[string]i2c.BusCloser{}
+	defer func() {
+		for _, b := range buses {
+			_ = b.Close()
+		}
+	}()
+	out := make([]i2cTxResult, 0, len(in))
+	for _, t := range in {
+		out = append(out, runI2CTx(buses, &t))
+	}
+	return out, 200
+}
+
+// runI2CTx runs a single I²C transaction, opening the bus if it was not
+// already opened.
+func runI2CTx(buses map[string]i2c.BusCloser, t *i2cTx) i2cTxResult {
+	if t.Addr >= 1<<10 {
+		return i2cTxResult{Err: "invalid address"}
+	}
+	if t.R < 0 || t.R > maxTxSize {
+		return i2cTxResult{Err: "invalid read length"}
+	}
+	bus := buses[t.Bus]
+	if bus == nil {
+		var err error
+		if bus, err = i2creg.Open(t.Bus); err != nil {
+			return i2cTxResult{Err: err.Error()}
+		}
+		buses[t.Bus] = bus
+	}
+	r := make(byteList, t.R)
+	d := i2c.Dev{Bus: bus, Addr: t.Addr}
+	if err := d.Tx(t.W, r); err != nil {
+		return i2cTxResult{Err: err.Error()}
+	}
+	return i2cTxResult{R: r}
+}
+
 // /api/spi/list
 
 type spiRef struct {
@@ -260,6 +564,137 @@ func (j *jsonAPI) apiSPIList() ([]spiRef, int) {
 	return out, 200
 }
 
+// /api/periph/v1/spi/tx
+
+// spiTx describes either a single SPI transaction or a list of packets to run
+// on the port Port.
+//
+// Freq is parsed with physic.Frequency.Set(), e.g. "4MHz"; it defaults to
+// 1MHz. Mode is the CLK and data polarity between 0 and 3. Bits defaults to 8.
+//
+// When Packets is empty, W is written and R bytes are read; R defaults to
+// len(W) since SPI is full duplex by default.
+type spiTx struct {
+	Port    string
+	Freq    string
+	Mode    int
+	Half    bool
+	NoCS    bool
+	LSB     bool
+	Bits    int
+	W       byteList
+	R       int
+	Packets []spiPacket
+}
+
+// spiPacket is the JSON representation of a spi.Packet.
+type spiPacket struct {
+	W           byteList
+	R           int
+	BitsPerWord uint8
+	KeepCS      bool
+}
+
+type spiTxResult struct {
+	// R is set when Packets was not specified.
+	R byteList
+	// Packets is set when Packets was specified, one item per packet.
+	Packets []byteList
+	Err     string
+}
+
+func (j *jsonAPI) apiSPITx(c *caller, in *spiTx) (*spiTxResult, int) {
+	k, err := spiKey(in.Port)
+	if err != nil {
+		return &spiTxResult{Err: err.Error()}, 400
+	}
+	if err = j.leases.check(c, k); err != nil {
+		return &spiTxResult{Err: err.Error()}, 409
+	}
+	defer j.locks.lock(k)()
+	return runSPITx(in), 200
+}
+
+// runSPITx connects to the port and runs the transaction.
+func runSPITx(in *spiTx) *spiTxResult {
+	conn, closer, err := connectSPI(in)
+	if err != nil {
+		return &spiTxResult{Err: err.Error()}
+	}
+	defer closer.Close()
+	if len(in.Packets) == 0 {
+		n := in.R
+		if n == 0 && !in.Half {
+			n = len(in.W)
+		}
+		if n < 0 || n > maxTxSize {
+			return &spiTxResult{Err: "invalid read length"}
+		}
+		r := make(byteList, n)
+		if err = conn.Tx(in.W, r); err != nil {
+			return &spiTxResult{Err: err.Error()}
+		}
+		return &spiTxResult{R: r}
+	}
+	p := make([]spi.Packet, len(in.Packets))
+	for i, src := range in.Packets {
+		if src.R < 0 || src.R > maxTxSize {
+			return &spiTxResult{Err: fmt.Sprintf("packet %d: invalid read length", i)}
+		}
+		p[i] = spi.Packet{W: src.W, R: make([]byte, src.R), BitsPerWord: src.BitsPerWord, KeepCS: src.KeepCS}
+	}
+	if err = conn.TxPackets(p); err != nil {
+		return &spiTxResult{Err: err.Error()}
+	}
+	out := &spiTxResult{Packets: make([]byteList, len(p))}
+	for i := range p {
+		out.Packets[i] = p[i].R
+	}
+	return out
+}
+
+// connectSPI opens the port and connects to it with the parameters in t.
+//
+// This mirrors what spi-io does.
+func connectSPI(t *spiTx) (spi.Conn, spi.PortCloser, error) {
+	hz := physic.MegaHertz
+	if t.Freq != "" {
+		if err := hz.Set(t.Freq); err != nil {
+			return nil, nil, err
+		}
+	}
+	if t.Mode < 0 || t.Mode > 3 {
+		return nil, nil, errors.New("invalid mode")
+	}
+	bits := t.Bits
+	if bits == 0 {
+		bits = 8
+	}
+	if bits < 1 || bits > 255 {
+		return nil, nil, errors.New("invalid bits")
+	}
+	m := spi.Mode(t.Mode)
+	if t.Half {
+		m |= spi.HalfDuplex
+	}
+	if t.NoCS {
+		m |= spi.NoCS
+	}
+	if t.LSB {
+		m |= spi.LSBFirst
+	}
+	p, err := spireg.Open(t.Port)
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := p.Connect(hz, m, bits)
+	if err != nil {
+		_ = p.Close()
+		return nil, nil, err
+	}
+	return c, p, nil
+}
+
 // /api/periph/v1/server/state
 
 type serverStateOut struct {
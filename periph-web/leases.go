@@ -0,0 +1,565 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/conn/v3/i2c/i2creg"
+	"periph.io/x/conn/v3/onewire/onewirereg"
+	"periph.io/x/conn/v3/spi/spireg"
+)
+
+const (
+	// defaultLeaseTTL is the duration of a lease when none is specified.
+	defaultLeaseTTL = time.Minute
+	// maxLeaseTTL is the maximum duration of a lease. Clients must renew their
+	// lease to hold the resources longer.
+	maxLeaseTTL = time.Hour
+)
+
+// leaseHeader is the HTTP header the clients use to pass their lease ID on
+// API calls that modify a leased resource.
+const leaseHeader = "X-Periph-Lease"
+
+// caller identifies the client of a JSON API call. JSON API handlers that
+// accept a *caller as their first argument receive it.
+type caller struct {
+	// lease is the lease ID passed in the X-Periph-Lease header, if any.
+	lease string
+	// user is the authenticated user or the remote IP.
+	user string
+	// remote is the remote address of the connection and endpoint the URL
+	// path or the MQTT topic, recorded in the audit log.
+	remote   string
+	endpoint string
+}
+
+var callerType = reflect.TypeOf((*caller)(nil))
+
+// errLeaseNotFound is returned when renewing or releasing an unknown lease.
+var errLeaseNotFound = errors.New("lease not found or expired")
+
+// leaseConflict is returned when a resource is leased by another client.
+type leaseConflict struct {
+	name   string
+	holder string
+}
+
+func (l *leaseConflict) Error() string {
+	return fmt.Sprintf("%s is leased by %s", l.name, l.holder)
+}
+
+// lease is the exclusive ownership of pins and buses by a client.
+type lease struct {
+	id     string
+	holder string
+	user   string
+	// pins are the state of the GPIOs before they were leased.
+	pins    []pinState
+	i2c     []string
+	spi     []string
+	onewire []string
+	expires time.Time
+	timer   *time.Timer
+}
+
+// resources returns the keys of the resources held by the lease.
+func (l *lease) resources() []string {
+	out := make([]string, 0, len(l.pins)+len(l.i2c)+len(l.spi)+len(l.onewire))
+	for _, p := range l.pins {
+		out = append(out, "gpio:"+p.p.Name())
+	}
+	for _, n := range l.i2c {
+		out = append(out, "i2c:"+n)
+	}
+	for _, n := range l.spi {
+		out = append(out, "spi:"+n)
+	}
+	for _, n := range l.onewire {
+		out = append(out, "onewire:"+n)
+	}
+	return out
+}
+
+// leases tracks the active leases.
+//
+// Resources are identified by a key "gpio:<name>", "i2c:<name>", "spi:<name>"
+// or "onewire:<name>", where name is the real name of the pin or bus.
+type leases struct {
+	mu sync.Mutex
+	// byID are the active leases.
+	byID map[string]*lease
+	// owner is the lease holding each leased resource.
+	owner map[string]*lease
+	// closed is set on shutdown, after which no lease expires anymore.
+	closed bool
+}
+
+// check returns a *leaseConflict if any of the resources is leased by another
+// client than c.
+func (l *leases) check(c *caller, keys ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, k := range keys {
+		if o := l.owner[k]; o != nil && o.id != c.lease {
+			return &leaseConflict{k[strings.IndexByte(k, ':')+1:], o.holder}
+		}
+	}
+	return nil
+}
+
+// acquire creates a lease on the resources requested by in for ttl. expire
+// is called with the lease ID when it expires.
+//
+// The resources are looked up, snapshotted and leased under the same lock, so
+// concurrent requests can't both lease a resource or snapshot a pin being
+// leased. It returns a *unknownBusError if a resource doesn't exist and a
+// *leaseConflict if one is leased.
+func (l *leases) acquire(c *caller, in *leaseIn, ttl time.Duration, expire func(id string)) (*lease, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	n := &lease{
+		id:      hex.EncodeToString(id[:]),
+		holder:  in.Holder,
+		user:    c.user,
+		expires: time.Now().Add(ttl),
+	}
+	if n.holder == "" {
+		n.holder = c.user
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, name := range in.Pins {
+		p := gpioreg.ByName(name)
+		if p == nil {
+			return nil, &unknownBusError{"pin", name}
+		}
+		n.pins = append(n.pins, snapshotPin(realPin(p)))
+	}
+	for _, name := range in.I2C {
+		r, ok := i2cName(name)
+		if !ok {
+			return nil, &unknownBusError{"I²C bus", name}
+		}
+		n.i2c = append(n.i2c, r)
+	}
+	for _, name := range in.SPI {
+		r, ok := spiName(name)
+		if !ok {
+			return nil, &unknownBusError{"SPI port", name}
+		}
+		n.spi = append(n.spi, r)
+	}
+	for _, name := range in.OneWire {
+		r, ok := onewireName(name)
+		if !ok {
+			return nil, &unknownBusError{"1-Wire bus", name}
+		}
+		n.onewire = append(n.onewire, r)
+	}
+	keys := n.resources()
+	for _, k := range keys {
+		if o := l.owner[k]; o != nil {
+			return nil, &leaseConflict{k[strings.IndexByte(k, ':')+1:], o.holder}
+		}
+	}
+	if l.byID == nil {
+		l.byID = map[string]*lease{}
+		l.owner = map[string]*lease{}
+	}
+	for _, k := range keys {
+		l.owner[k] = n
+	}
+	l.byID[n.id] = n
+	n.timer = time.AfterFunc(ttl, func() { expire(n.id) })
+	return n, nil
+}
+
+// renew extends the lease id by ttl from now.
+func (l *leases) renew(id string, ttl time.Duration) (time.Time, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := l.byID[id]
+	if n == nil || !n.timer.Stop() {
+		// The timer already fired; the lease is being released.
+		return time.Time{}, errLeaseNotFound
+	}
+	n.expires = time.Now().Add(ttl)
+	n.timer.Reset(ttl)
+	return n.expires, nil
+}
+
+// release removes the lease id and calls restore on it while holding the lock,
+// so no other client can lease the resources before they are restored.
+func (l *leases) release(id string, restore func(n *lease)) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := l.byID[id]
+	if n == nil || l.closed {
+		return errLeaseNotFound
+	}
+	n.timer.Stop()
+	delete(l.byID, id)
+	for _, k := range n.resources() {
+		delete(l.owner, k)
+	}
+	restore(n)
+	return nil
+}
+
+// close stops the expiration timers. A timer that fired concurrently finds
+// the leases closed and doesn't restore its pins.
+func (l *leases) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	for _, n := range l.byID {
+		n.timer.Stop()
+	}
+}
+
+// list returns a copy of the active leases sorted by expiration.
+func (l *leases) list() []leaseInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]leaseInfo, 0, len(l.byID))
+	for _, n := range l.byID {
+		i := leaseInfo{Holder: n.holder, User: n.user, I2C: n.i2c, SPI: n.spi, OneWire: n.onewire, Expires: n.expires}
+		for _, p := range n.pins {
+			i.Pins = append(i.Pins, p.p.Name())
+		}
+		out = append(out, i)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Expires.Before(out[j].Expires) })
+	return out
+}
+
+// resourceLocks serializes the use of the pins and buses, identified by their
+// resource key. A sequence holds the locks of all its resources until it
+// completes, so the single calls of the lease holder can't interleave with it.
+type resourceLocks struct {
+	mu   sync.Mutex
+	cond sync.Cond
+	held map[string]bool
+}
+
+// lock waits until none of the resources is used then locks all of them at
+// once, so two callers locking overlapping resources can't deadlock. It
+// returns the function unlocking them.
+func (r *resourceLocks) lock(keys ...string) func() {
+	r.mu.Lock()
+	if r.held == nil {
+		r.held = map[string]bool{}
+		r.cond.L = &r.mu
+	}
+	for r.anyHeldLocked(keys) {
+		r.cond.Wait()
+	}
+	for _, k := range keys {
+		r.held[k] = true
+	}
+	r.mu.Unlock()
+	return func() {
+		r.mu.Lock()
+		for _, k := range keys {
+			delete(r.held, k)
+		}
+		r.mu.Unlock()
+		r.cond.Broadcast()
+	}
+}
+
+func (r *resourceLocks) anyHeldLocked(keys []string) bool {
+	for _, k := range keys {
+		if r.held[k] {
+			return true
+		}
+	}
+	return false
+}
+
+// pinKey returns the resource key of a GPIO. Aliases resolve to the real pin.
+func pinKey(p gpio.PinIO) string {
+	return "gpio:" + realPin(p).Name()
+}
+
+func realPin(p gpio.PinIO) gpio.PinIO {
+	if r, ok := p.(gpio.RealPin); ok {
+		return r.Real()
+	}
+	return p
+}
+
+// i2cName returns the real name of the I²C bus name, which can be an alias
+// or a number. It resolves name like i2creg.Open, so an empty name is the
+// default bus.
+func i2cName(name string) (string, bool) {
+	var refs []busRef
+	for _, ref := range i2creg.All() {
+		refs = append(refs, busRef{ref.Name, ref.Aliases, ref.Number})
+	}
+	return resolveRef(name, refs)
+}
+
+// spiName returns the real name of the SPI port name, which can be an alias
+// or a number. It resolves name like spireg.Open, so an empty name is the
+// default port.
+func spiName(name string) (string, bool) {
+	var refs []busRef
+	for _, ref := range spireg.All() {
+		refs = append(refs, busRef{ref.Name, ref.Aliases, ref.Number})
+	}
+	return resolveRef(name, refs)
+}
+
+// onewireName returns the real name of the 1-Wire bus name, which can be an
+// alias or a number, like i2cName.
+func onewireName(name string) (string, bool) {
+	var refs []busRef
+	for _, ref := range onewirereg.All() {
+		refs = append(refs, busRef{ref.Name, ref.Aliases, ref.Number})
+	}
+	return resolveRef(name, refs)
+}
+
+// busRef is the part of an i2creg.Ref, a spireg.Ref or a onewirereg.Ref used
+// to resolve a name.
+type busRef struct {
+	name    string
+	aliases []string
+	number  int
+}
+
+// resolveRef returns the name of the ref that i2creg.Open, spireg.Open or
+// onewirereg.Open opens for name. refs must be sorted by name, as returned by All().
+//
+// An empty name selects the ref with the lowest number, or the first one if
+// none has a number. Otherwise name is looked up by name, by alias then by
+// number.
+func resolveRef(name string, refs []busRef) (string, bool) {
+	if name == "" {
+		var def *busRef
+		for i := range refs {
+			if refs[i].number != -1 && (def == nil || refs[i].number < def.number) {
+				def = &refs[i]
+			}
+		}
+		if def == nil && len(refs) != 0 {
+			def = &refs[0]
+		}
+		if def == nil {
+			return "", false
+		}
+		return def.name, true
+	}
+	for _, r := range refs {
+		if r.name == name {
+			return r.name, true
+		}
+	}
+	for _, r := range refs {
+		for _, a := range r.aliases {
+			if a == name {
+				return r.name, true
+			}
+		}
+	}
+	if n, err := strconv.Atoi(name); err == nil && n != -1 {
+		for _, r := range refs {
+			if r.number == n {
+				return r.name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// i2cKey returns the resource key of the I²C bus name. The key is the same
+// for all the names of a bus, so a lease can't be bypassed by using an alias
+// or the default bus.
+func i2cKey(name string) (string, error) {
+	n, ok := i2cName(name)
+	if !ok {
+		return "", &unknownBusError{"I²C bus", name}
+	}
+	return "i2c:" + n, nil
+}
+
+// spiKey returns the resource key of the SPI port name, like i2cKey.
+func spiKey(name string) (string, error) {
+	n, ok := spiName(name)
+	if !ok {
+		return "", &unknownBusError{"SPI port", name}
+	}
+	return "spi:" + n, nil
+}
+
+// onewireKey returns the resource key of the 1-Wire bus name, like i2cKey.
+func onewireKey(name string) (string, error) {
+	n, ok := onewireName(name)
+	if !ok {
+		return "", &unknownBusError{"1-Wire bus", name}
+	}
+	return "onewire:" + n, nil
+}
+
+// unknownBusError is returned when a pin, bus or port name can't be resolved.
+// The handlers return 400 so the lease check is not bypassed.
+type unknownBusError struct {
+	kind string
+	name string
+}
+
+func (e *unknownBusError) Error() string {
+	return fmt.Sprintf("%s %q not found", e.kind, e.name)
+}
+
+// /api/periph/v1/lease/acquire
+
+// leaseIn is a request to lease pins and buses.
+type leaseIn struct {
+	// Holder is the name shown to other clients. It defaults to the
+	// authenticated user or the remote IP.
+	Holder string
+	// Pins are the GPIOs to lease. They are restored to their current state
+	// when the lease is released.
+	Pins []string
+	// I2C are the I²C buses to lease.
+	I2C []string
+	// SPI are the SPI ports to lease.
+	SPI []string
+	// OneWire are the 1-Wire buses to lease.
+	OneWire []string
+	// TTL is parsed with time.ParseDuration(), e.g. "30s"; it defaults to 1m
+	// and is capped at 1h.
+	TTL string
+}
+
+type leaseOut struct {
+	// ID is the lease ID to pass in the X-Periph-Lease header.
+	ID      string
+	Expires time.Time
+	Err     string
+}
+
+// apiLeaseAcquire leases all the resources or none. It returns 400 if a
+// resource doesn't exist and 409 if any resource is already leased.
+func (j *jsonAPI) apiLeaseAcquire(c *caller, in *leaseIn) (*leaseOut, int) {
+	ttl, err := parseTTL(in.TTL)
+	if err != nil {
+		return &leaseOut{Err: err.Error()}, 200
+	}
+	if len(in.Pins) == 0 && len(in.I2C) == 0 && len(in.SPI) == 0 && len(in.OneWire) == 0 {
+		return &leaseOut{Err: "nothing to lease"}, 200
+	}
+	n, err := j.leases.acquire(c, in, ttl, j.expireLease)
+	if err != nil {
+		switch err.(type) {
+		case *unknownBusError:
+			return &leaseOut{Err: err.Error()}, 400
+		case *leaseConflict:
+			return &leaseOut{Err: err.Error()}, 409
+		}
+		return &leaseOut{Err: err.Error()}, 200
+	}
+	return &leaseOut{ID: n.id, Expires: n.expires}, 200
+}
+
+func parseTTL(s string) (time.Duration, error) {
+	if s == "" {
+		return defaultLeaseTTL, nil
+	}
+	ttl, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if ttl <= 0 {
+		return 0, errors.New("invalid TTL")
+	}
+	if ttl > maxLeaseTTL {
+		ttl = maxLeaseTTL
+	}
+	return ttl, nil
+}
+
+// expireLease is called when a lease expires.
+func (j *jsonAPI) expireLease(id string) {
+	_ = j.leases.release(id, func(n *lease) { j.restoreLease(n, "lease/expire") })
+}
+
+// restoreLease restores the pins of the lease to their prior state and
+// records it in the audit log for reason.
+func (j *jsonAPI) restoreLease(n *lease, reason string) {
+	names := make([]string, 0, len(n.pins))
+	errs := make([]error, 0, len(n.pins))
+	for i := range n.pins {
+		j.events.unwatch(n.pins[i].p.Name())
+		names = append(names, n.pins[i].p.Name())
+		errs = append(errs, n.pins[i].restore())
+	}
+	j.audit.recordRestore(reason, names, errs)
+}
+
+// /api/periph/v1/lease/renew
+
+// leaseRef refers to a lease previously acquired.
+type leaseRef struct {
+	ID string
+	// TTL is only used when renewing. It has the same format as leaseIn.TTL.
+	TTL string
+}
+
+func (j *jsonAPI) apiLeaseRenew(in *leaseRef) (*leaseOut, int) {
+	ttl, err := parseTTL(in.TTL)
+	if err != nil {
+		return &leaseOut{Err: err.Error()}, 200
+	}
+	exp, err := j.leases.renew(in.ID, ttl)
+	if err != nil {
+		return &leaseOut{Err: err.Error()}, 200
+	}
+	return &leaseOut{ID: in.ID, Expires: exp}, 200
+}
+
+// /api/periph/v1/lease/release
+
+func (j *jsonAPI) apiLeaseRelease(in *leaseRef) (*leaseOut, int) {
+	if err := j.leases.release(in.ID, func(n *lease) { j.restoreLease(n, "lease/release") }); err != nil {
+		return &leaseOut{Err: err.Error()}, 200
+	}
+	return &leaseOut{ID: in.ID}, 200
+}
+
+// /api/periph/v1/lease/list
+
+// leaseInfo is an active lease. It doesn't contain the lease ID, which is
+// only known to its holder.
+type leaseInfo struct {
+	Holder  string
+	User    string
+	Pins    []string
+	I2C     []string
+	SPI     []string
+	OneWire []string
+	Expires time.Time
+}
+
+func (j *jsonAPI) apiLeaseList() ([]leaseInfo, int) {
+	return j.leases.list(), 200
+}
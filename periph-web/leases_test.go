@@ -0,0 +1,69 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestWebLeaseAcquireUnknown verifies that leasing a resource that doesn't
+// exist is rejected like the other handlers do.
+func TestWebLeaseAcquireUnknown(t *testing.T) {
+	registerFakes(t)
+	ts := newTestServer(t, &webOpts{})
+	for _, in := range []*leaseIn{
+		{Pins: []string{"WEB1", "NOPE"}},
+		{I2C: []string{"NOPE"}},
+		{SPI: []string{"WEBSPI", "NOPE"}},
+	} {
+		var out leaseOut
+		ts.post(t, "/api/periph/v1/lease/acquire", in, &out, 400)
+		if out.ID != "" || out.Err == "" {
+			t.Fatal(out)
+		}
+	}
+	// Nothing was leased.
+	var out []leaseInfo
+	ts.post(t, "/api/periph/v1/lease/list", map[string]string{}, &out, 200)
+	if len(out) != 0 {
+		t.Fatal(out)
+	}
+}
+
+// TestLeasesAcquireConcurrent verifies that only one of concurrent requests
+// for the same resources gets them.
+func TestLeasesAcquireConcurrent(t *testing.T) {
+	registerFakes(t)
+	var l leases
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var won []*lease
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// The same bus under different names.
+			names := []string{"WEBI2C", "99"}
+			n, err := l.acquire(&caller{user: "test"}, &leaseIn{Pins: []string{"WEB_ALIAS"}, I2C: names[i%2:][:1]}, time.Minute, func(string) {})
+			if err == nil {
+				mu.Lock()
+				won = append(won, n)
+				mu.Unlock()
+			} else if _, ok := err.(*leaseConflict); !ok {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(won) != 1 {
+		t.Fatalf("%d leases", len(won))
+	}
+	if n := won[0]; len(n.pins) != 1 || n.pins[0].p.Name() != "WEB1" || n.i2c[0] != "WEBI2C" {
+		t.Fatal(n)
+	}
+	_ = l.release(won[0].id, func(*lease) {})
+}
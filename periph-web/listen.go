@@ -0,0 +1,140 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// unixPrefix is the -http prefix to listen on a Unix domain socket, e.g.
+// "unix:/run/periph-web.sock".
+const unixPrefix = "unix:"
+
+// listenFDsStart is the first file descriptor passed by systemd socket
+// activation.
+const listenFDsStart = 3
+
+// listen returns the listener to serve on.
+//
+// The socket passed by systemd socket activation has precedence over
+// hostport. Otherwise hostport is either "unix:<path>" or "<host>:<port>".
+func listen(hostport string, unixMode os.FileMode) (net.Listener, error) {
+	ln, err := systemdListener()
+	if ln != nil || err != nil {
+		return ln, err
+	}
+	if strings.HasPrefix(hostport, unixPrefix) {
+		return listenUnix(strings.TrimPrefix(hostport, unixPrefix), unixMode)
+	}
+	host, port, err := getHostAndPort(hostport)
+	if err != nil {
+		return nil, err
+	}
+	return net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+}
+
+// systemdListener returns the socket passed by systemd socket activation, if
+// any.
+//
+// See https://www.freedesktop.org/software/systemd/man/sd_listen_fds.html
+func systemdListener() (net.Listener, error) {
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	// Do not pass the socket to child processes.
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDS")
+	_ = os.Unsetenv("LISTEN_FDNAMES")
+	if err != nil {
+		return nil, fmt.Errorf("invalid LISTEN_FDS: %v", err)
+	}
+	if n != 1 {
+		return nil, fmt.Errorf("expected exactly one socket from systemd, got %d", n)
+	}
+	f := os.NewFile(listenFDsStart, "systemd")
+	// FileListener duplicates the file descriptor.
+	defer f.Close()
+	return net.FileListener(f)
+}
+
+// listenUnix listens on a Unix domain socket at path with the permissions
+// mode. Only the users allowed to write to the socket can connect.
+//
+// A stale socket left by a previous process is removed.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if c, err := net.Dial("unix", path); err == nil {
+			_ = c.Close()
+			return nil, fmt.Errorf("%s is already in use", path)
+		}
+		if err = os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Chmod(path, mode); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// isUnix returns true if the listener is a Unix domain socket.
+func isUnix(ln net.Listener) bool {
+	_, ok := ln.Addr().(*net.UnixAddr)
+	return ok
+}
+
+// isLoopback returns true if the listener only accepts connections from the
+// host itself.
+func isLoopback(ln net.Listener) bool {
+	a, ok := ln.Addr().(*net.TCPAddr)
+	return ok && a.IP.IsLoopback()
+}
+
+// peerKey is the context key for the identity of the peer of a Unix domain
+// socket connection.
+type peerKey struct{}
+
+// peerContext stores the identity of the peer of c in the context, when
+// available. It is used as http.Server.ConnContext.
+func peerContext(ctx context.Context, c net.Conn) context.Context {
+	if id := peerID(c); id != "" {
+		return context.WithValue(ctx, peerKey{}, id)
+	}
+	return ctx
+}
+
+// sdNotify sends a state change to systemd, e.g. "READY=1". It does nothing
+// when not running as a systemd service with Type=notify.
+//
+// See https://www.freedesktop.org/software/systemd/man/sd_notify.html
+func sdNotify(state string) error {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return nil
+	}
+	if addr[0] != '/' && addr[0] != '@' {
+		return errors.New("unsupported NOTIFY_SOCKET")
+	}
+	// Go maps a leading '@' to the Linux abstract namespace.
+	c, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	_, err = c.Write([]byte(state))
+	return err
+}
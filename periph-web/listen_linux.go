@@ -0,0 +1,36 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/tls"
+	"net"
+	"strconv"
+	"syscall"
+)
+
+// peerID returns "uid:<uid>" for the peer of a Unix domain socket connection,
+// as reported by the kernel.
+func peerID(c net.Conn) string {
+	if t, ok := c.(*tls.Conn); ok {
+		c = t.NetConn()
+	}
+	u, ok := c.(*net.UnixConn)
+	if !ok {
+		return ""
+	}
+	raw, err := u.SyscallConn()
+	if err != nil {
+		return ""
+	}
+	var cred *syscall.Ucred
+	var cerr error
+	if err = raw.Control(func(fd uintptr) {
+		cred, cerr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	}); err != nil || cerr != nil {
+		return ""
+	}
+	return "uid:" + strconv.FormatUint(uint64(cred.Uid), 10)
+}
@@ -0,0 +1,15 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+//go:build !linux
+// +build !linux
+
+package main
+
+import "net"
+
+// peerID is only implemented on linux.
+func peerID(c net.Conn) string {
+	return ""
+}
@@ -18,12 +18,7 @@ import (
 func loggingHandler(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		received := time.Now()
-		rwh := responseWriteHijacker{responseWriter: responseWriter{ResponseWriter: w}}
-		w = &rwh
-		// Not all ResponseWriter implement Hijack, so query its support upfront.
-		if _, ok := w.(http.Hijacker); !ok {
-			w = &rwh.responseWriter
-		}
+		w, rwh := wrapResponseWriter(w)
 		defer func() {
 			m := r.Method
 			if rwh.hijacked {
@@ -35,6 +30,18 @@ func loggingHandler(h http.Handler) http.Handler {
 	})
 }
 
+// wrapResponseWriter wraps w to record the status and the length of the
+// response. The returned http.ResponseWriter is rwh, which implements
+// http.Hijacker only if w does.
+func wrapResponseWriter(w http.ResponseWriter) (http.ResponseWriter, *responseWriteHijacker) {
+	rwh := &responseWriteHijacker{responseWriter: responseWriter{ResponseWriter: w}}
+	// Not all ResponseWriter implement Hijack, so query its support upfront.
+	if _, ok := w.(http.Hijacker); !ok {
+		return &rwh.responseWriter, rwh
+	}
+	return rwh, rwh
+}
+
 type responseWriter struct {
 	http.ResponseWriter
 	length int
@@ -55,6 +62,18 @@ func (r *responseWriter) WriteHeader(status int) {
 	r.status = status
 }
 
+// Flush is needed for Server-Sent Events.
+func (r *responseWriter) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap is used by http.ResponseController.
+func (r *responseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 type responseWriteHijacker struct {
 	responseWriter
 	hijacked bool
@@ -0,0 +1,187 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+)
+
+// latencyBuckets are the upper bounds in seconds of the request latency
+// histogram.
+var latencyBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+type requestKey struct {
+	path string
+	code int
+}
+
+// histogram is a Prometheus histogram.
+type histogram struct {
+	// counts has one more item than latencyBuckets for +Inf.
+	counts []int64
+	sum    float64
+}
+
+// httpMetrics records the HTTP requests served.
+type httpMetrics struct {
+	mu       sync.Mutex
+	requests map[requestKey]int64
+	latency  map[string]*histogram
+}
+
+func (m *httpMetrics) record(path string, code int, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.requests == nil {
+		m.requests = map[requestKey]int64{}
+		m.latency = map[string]*histogram{}
+	}
+	m.requests[requestKey{path, code}]++
+	h := m.latency[path]
+	if h == nil {
+		h = &histogram{counts: make([]int64, len(latencyBuckets)+1)}
+		m.latency[path] = h
+	}
+	s := d.Seconds()
+	i := sort.SearchFloat64s(latencyBuckets, s)
+	h.counts[i]++
+	h.sum += s
+}
+
+func (m *httpMetrics) write(b *bytes.Buffer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].path != keys[j].path {
+			return keys[i].path < keys[j].path
+		}
+		return keys[i].code < keys[j].code
+	})
+	b.WriteString("# HELP periph_http_requests_total HTTP requests served by path and status code.\n")
+	b.WriteString("# TYPE periph_http_requests_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(b, "periph_http_requests_total{path=%s,code=\"%d\"} %d\n", quoteLabel(k.path), k.code, m.requests[k])
+	}
+	paths := make([]string, 0, len(m.latency))
+	for p := range m.latency {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	b.WriteString("# HELP periph_http_request_duration_seconds HTTP request latency by path.\n")
+	b.WriteString("# TYPE periph_http_request_duration_seconds histogram\n")
+	for _, p := range paths {
+		h := m.latency[p]
+		l := quoteLabel(p)
+		total := int64(0)
+		for i, le := range latencyBuckets {
+			total += h.counts[i]
+			fmt.Fprintf(b, "periph_http_request_duration_seconds_bucket{path=%s,le=\"%s\"} %d\n", l, formatFloat(le), total)
+		}
+		total += h.counts[len(latencyBuckets)]
+		fmt.Fprintf(b, "periph_http_request_duration_seconds_bucket{path=%s,le=\"+Inf\"} %d\n", l, total)
+		fmt.Fprintf(b, "periph_http_request_duration_seconds_sum{path=%s} %s\n", l, formatFloat(h.sum))
+		fmt.Fprintf(b, "periph_http_request_duration_seconds_count{path=%s} %d\n", l, total)
+	}
+}
+
+// metricsHandler records the requests in m.
+//
+// The path label is the pattern of the handler that serves the request, so
+// the number of time series stays bounded.
+func metricsHandler(m *httpMetrics, mux *http.ServeMux, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received := time.Now()
+		_, pattern := mux.Handler(r)
+		w, rw := wrapResponseWriter(w)
+		defer func() {
+			code := rw.status
+			if code == 0 {
+				code = 200
+			}
+			m.record(pattern, code, time.Since(received))
+		}()
+		h.ServeHTTP(w, r)
+	})
+}
+
+// /metrics
+
+// getMetrics returns the metrics in the Prometheus text format.
+func (s *webServer) getMetrics(w http.ResponseWriter, r *http.Request) {
+	var b bytes.Buffer
+	s.apis.writeMetrics(&b)
+	s.metrics.write(&b)
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", cacheControlNone)
+	_, _ = w.Write(b.Bytes())
+}
+
+// writeMetrics writes the drivers state, the level of the GPIOs set as input
+// and the measurements of the configured sensors.
+//
+// The sensors are not read here, so the scrape interval doesn't dictate the
+// bus traffic; the measurements are the last ones sampled for the history, if
+// enabled.
+func (j *jsonAPI) writeMetrics(b *bytes.Buffer) {
+	b.WriteString("# HELP periph_driver_state Driver state; 1 for the state the driver is in.\n")
+	b.WriteString("# TYPE periph_driver_state gauge\n")
+	for _, d := range j.state.Loaded {
+		fmt.Fprintf(b, "periph_driver_state{driver=%s,state=\"loaded\"} 1\n", quoteLabel(d))
+	}
+	for _, d := range j.state.Skipped {
+		fmt.Fprintf(b, "periph_driver_state{driver=%s,state=\"skipped\"} 1\n", quoteLabel(d.D))
+	}
+	for _, d := range j.state.Failed {
+		fmt.Fprintf(b, "periph_driver_state{driver=%s,state=\"failed\"} 1\n", quoteLabel(d.D))
+	}
+	b.WriteString("# HELP periph_gpio_level Level of the GPIOs set as input.\n")
+	b.WriteString("# TYPE periph_gpio_level gauge\n")
+	for _, p := range gpioreg.All() {
+		if pinDirection(p) != gpio.IN {
+			continue
+		}
+		v := 0
+		if p.Read() {
+			v = 1
+		}
+		fmt.Fprintf(b, "periph_gpio_level{pin=%s} %d\n", quoteLabel(p.Name()), v)
+	}
+	b.WriteString("# HELP periph_sensor_value Last measurement of the sensors in the -devices configuration file, sampled every -history interval.\n")
+	b.WriteString("# TYPE periph_sensor_value gauge\n")
+	if j.history == nil {
+		return
+	}
+	for _, d := range j.devices {
+		if d.sense == nil {
+			continue
+		}
+		for _, v := range j.history.lastSensed(d.cfg.Name) {
+			fmt.Fprintf(b, "periph_sensor_value{device=%s,quantity=%s,unit=%s} %s\n", quoteLabel(d.cfg.Name), quoteLabel(v.Quantity), quoteLabel(v.Unit), formatFloat(v.Value))
+		}
+	}
+}
+
+// quoteLabel returns the label value quoted per the Prometheus text format.
+func quoteLabel(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"periph.io/x/conn/v3/driver/driverreg"
+	"periph.io/x/conn/v3/gpio"
+)
+
+// TestWriteMetrics verifies that the sensors are not read on scrape.
+func TestWriteMetrics(t *testing.T) {
+	f := registerFakes(t)
+	_ = f.pins[0].In(gpio.PullNoChange, gpio.NoEdge)
+	f.pins[0].L = gpio.High
+	reads := 0
+	var j jsonAPI
+	j.init("localhost", &driverreg.State{})
+	j.devices = []*device{{
+		cfg: deviceConfig{Name: "env"},
+		sense: func() ([]sensorValue, error) {
+			reads++
+			return []sensorValue{{Quantity: "temperature", Value: 21.5, Unit: "celsius"}}, nil
+		},
+	}}
+	var b bytes.Buffer
+	j.writeMetrics(&b)
+	if reads != 0 || strings.Contains(b.String(), "periph_sensor_value{") {
+		t.Fatal(reads, b.String())
+	}
+	if !strings.Contains(b.String(), `periph_gpio_level{pin="WEB1"} 1`) || strings.Contains(b.String(), `pin="WEB2"`) {
+		t.Fatal(b.String())
+	}
+
+	// With the history, the last sample is served.
+	j.history = &history{j: &j, opts: historyOpts{size: 2}, series: map[string]*ring{}, sensed: map[string][]sensorValue{}}
+	j.history.sample(time.Now())
+	if reads != 1 {
+		t.Fatal(reads)
+	}
+	for i := 0; i < 3; i++ {
+		b.Reset()
+		j.writeMetrics(&b)
+	}
+	if reads != 1 || !strings.Contains(b.String(), `periph_sensor_value{device="env",quantity="temperature",unit="celsius"} 21.5`) {
+		t.Fatal(reads, b.String())
+	}
+}
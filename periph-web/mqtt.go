@@ -0,0 +1,320 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// This file implements the small subset of MQTT 3.1.1 needed by the bridge:
+// QoS 0 publish and subscribe, last will, keep alive.
+//
+// See http://docs.oasis-open.org/mqtt/mqtt/v3.1.1/os/mqtt-v3.1.1-os.html
+
+// MQTT control packet types, shifted in the upper nibble of the first byte.
+const (
+	mqttConnect    = 1 << 4
+	mqttConnAck    = 2 << 4
+	mqttPublish    = 3 << 4
+	mqttPubAck     = 4 << 4
+	mqttSubscribe  = 8 << 4
+	mqttSubAck     = 9 << 4
+	mqttPingReq    = 12 << 4
+	mqttPingResp   = 13 << 4
+	mqttDisconnect = 14 << 4
+)
+
+const (
+	// mqttKeepAlive is the keep alive interval sent to the broker. The client
+	// must ping more often than this.
+	mqttKeepAlive = 60 * time.Second
+	// mqttDialTimeout is the maximum time to connect to the broker.
+	mqttDialTimeout = 10 * time.Second
+	// mqttMaxPacket is the largest packet accepted from the broker.
+	mqttMaxPacket = 1 << 20
+)
+
+// mqttMessage is an application message.
+type mqttMessage struct {
+	topic   string
+	payload []byte
+	retain  bool
+}
+
+// mqttClient is a connection to a MQTT broker.
+type mqttClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+	// onMessage is called from readLoop() for every message received.
+	onMessage func(m *mqttMessage)
+
+	mu     sync.Mutex // Serializes writes.
+	nextID uint16
+}
+
+// dialMQTT connects to the broker at u and completes the MQTT handshake.
+//
+// The scheme is "tcp" or "mqtt" for plain text, "ssl", "tls" or "mqtts" for
+// TLS. The user and password are taken from u, if present. will is published
+// by the broker if the connection is lost.
+func dialMQTT(u *url.URL, clientID string, will *mqttMessage) (*mqttClient, error) {
+	d := net.Dialer{Timeout: mqttDialTimeout}
+	var conn net.Conn
+	var err error
+	switch u.Scheme {
+	case "tcp", "mqtt":
+		conn, err = d.Dial("tcp", hostPortDefault(u, "1883"))
+	case "ssl", "tls", "mqtts":
+		conn, err = tls.DialWithDialer(&d, "tcp", hostPortDefault(u, "8883"), &tls.Config{ServerName: u.Hostname()})
+	default:
+		return nil, fmt.Errorf("unsupported MQTT scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &mqttClient{conn: conn, r: bufio.NewReader(conn)}
+	if err = c.connect(u.User, clientID, will); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func hostPortDefault(u *url.URL, port string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+func (c *mqttClient) connect(user *url.Userinfo, clientID string, will *mqttMessage) error {
+	var b []byte
+	b = appendMQTTString(b, "MQTT")
+	// Protocol level 4 is MQTT 3.1.1.
+	b = append(b, 4)
+	flagsPos := len(b)
+	// Clean session.
+	b = append(b, 0x02)
+	b = binary.BigEndian.AppendUint16(b, uint16(mqttKeepAlive/time.Second))
+	b = appendMQTTString(b, clientID)
+	if will != nil {
+		b[flagsPos] |= 0x04
+		if will.retain {
+			b[flagsPos] |= 0x20
+		}
+		b = appendMQTTString(b, will.topic)
+		b = appendMQTTString(b, string(will.payload))
+	}
+	if user != nil {
+		b[flagsPos] |= 0x80
+		b = appendMQTTString(b, user.Username())
+		if p, ok := user.Password(); ok {
+			b[flagsPos] |= 0x40
+			b = appendMQTTString(b, p)
+		}
+	}
+	_ = c.conn.SetDeadline(time.Now().Add(mqttDialTimeout))
+	if err := c.write(mqttConnect, b); err != nil {
+		return err
+	}
+	t, body, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+	_ = c.conn.SetDeadline(time.Time{})
+	if t&0xF0 != mqttConnAck || len(body) != 2 {
+		return errors.New("mqtt: expected CONNACK")
+	}
+	switch body[1] {
+	case 0:
+		return nil
+	case 4:
+		return errors.New("mqtt: bad user name or password")
+	case 5:
+		return errors.New("mqtt: not authorized")
+	default:
+		return fmt.Errorf("mqtt: connection refused with code %d", body[1])
+	}
+}
+
+// publish sends a QoS 0 message.
+func (c *mqttClient) publish(m *mqttMessage) error {
+	t := byte(mqttPublish)
+	if m.retain {
+		t |= 0x01
+	}
+	return c.write(t, append(appendMQTTString(nil, m.topic), m.payload...))
+}
+
+// subscribe subscribes to the topic filters with QoS 0. The SUBACK is
+// processed by readLoop().
+func (c *mqttClient) subscribe(filters ...string) error {
+	c.mu.Lock()
+	c.nextID++
+	if c.nextID == 0 {
+		c.nextID = 1
+	}
+	id := c.nextID
+	c.mu.Unlock()
+	b := binary.BigEndian.AppendUint16(nil, id)
+	for _, f := range filters {
+		b = append(appendMQTTString(b, f), 0)
+	}
+	// SUBSCRIBE has the reserved flags 0b0010.
+	return c.write(mqttSubscribe|0x02, b)
+}
+
+func (c *mqttClient) ping() error {
+	return c.write(mqttPingReq, nil)
+}
+
+// close sends DISCONNECT so the broker doesn't publish the last will, then
+// closes the connection.
+func (c *mqttClient) close() error {
+	_ = c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+	_ = c.write(mqttDisconnect, nil)
+	return c.conn.Close()
+}
+
+// readLoop processes the packets sent by the broker until the connection is
+// closed or the broker doesn't answer pings anymore.
+func (c *mqttClient) readLoop() error {
+	for {
+		_ = c.conn.SetReadDeadline(time.Now().Add(mqttKeepAlive * 3 / 2))
+		t, body, err := c.readPacket()
+		if err != nil {
+			return err
+		}
+		switch t & 0xF0 {
+		case mqttPublish:
+			m, id, err := parseMQTTPublish(t, body)
+			if err != nil {
+				return err
+			}
+			if id != 0 {
+				if err = c.write(mqttPubAck, binary.BigEndian.AppendUint16(nil, id)); err != nil {
+					return err
+				}
+			}
+			if c.onMessage != nil {
+				c.onMessage(m)
+			}
+		case mqttSubAck:
+			if len(body) < 2 {
+				return errors.New("mqtt: truncated SUBACK")
+			}
+			for _, code := range body[2:] {
+				if code == 0x80 {
+					return errors.New("mqtt: subscription refused")
+				}
+			}
+		case mqttPingResp:
+		default:
+			return fmt.Errorf("mqtt: unexpected packet type %d", t>>4)
+		}
+	}
+}
+
+// parseMQTTPublish decodes a PUBLISH packet. It returns the packet ID to
+// acknowledge, if any.
+func parseMQTTPublish(t byte, body []byte) (*mqttMessage, uint16, error) {
+	topic, rest, err := readMQTTString(body)
+	if err != nil {
+		return nil, 0, err
+	}
+	id := uint16(0)
+	if qos := (t >> 1) & 3; qos != 0 {
+		if len(rest) < 2 {
+			return nil, 0, errors.New("mqtt: truncated PUBLISH")
+		}
+		// QoS 2 is never requested, so it is acknowledged as QoS 1.
+		id = binary.BigEndian.Uint16(rest)
+		rest = rest[2:]
+	}
+	return &mqttMessage{topic: topic, payload: rest, retain: t&0x01 != 0}, id, nil
+}
+
+func (c *mqttClient) write(t byte, body []byte) error {
+	b := append([]byte{t}, appendMQTTLength(nil, len(body))...)
+	b = append(b, body...)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.conn.Write(b)
+	return err
+}
+
+func (c *mqttClient) readPacket() (byte, []byte, error) {
+	return readMQTTPacket(c.r)
+}
+
+// readMQTTPacket reads a MQTT control packet and returns its first byte and
+// its variable header and payload.
+func readMQTTPacket(r *bufio.Reader) (byte, []byte, error) {
+	t, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	l := 0
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("mqtt: invalid remaining length")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		l |= int(b&0x7F) << (7 * i)
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	if l > mqttMaxPacket {
+		return 0, nil, errors.New("mqtt: packet too large")
+	}
+	body := make([]byte, l)
+	if _, err = io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return t, body, nil
+}
+
+func appendMQTTLength(b []byte, l int) []byte {
+	for {
+		d := byte(l & 0x7F)
+		l >>= 7
+		if l != 0 {
+			d |= 0x80
+		}
+		b = append(b, d)
+		if l == 0 {
+			return b
+		}
+	}
+}
+
+func appendMQTTString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+func readMQTTString(b []byte) (string, []byte, error) {
+	if len(b) < 2 {
+		return "", nil, errors.New("mqtt: truncated string")
+	}
+	l := int(binary.BigEndian.Uint16(b))
+	if len(b) < 2+l {
+		return "", nil, errors.New("mqtt: truncated string")
+	}
+	return string(b[2 : 2+l]), b[2+l:], nil
+}
@@ -0,0 +1,272 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/conn/v3/gpio/gpiotest"
+)
+
+func TestMQTTBridge(t *testing.T) {
+	p := &gpiotest.Pin{N: "MQTT1", Num: 1001}
+	in := &gpiotest.Pin{N: "MQTT2", Num: 1002, Fn: "In/High", L: gpio.High}
+	for _, q := range []*gpiotest.Pin{p, in} {
+		if err := gpioreg.Register(q); err != nil {
+			t.Fatal(err)
+		}
+		name := q.N
+		defer func() {
+			if err := gpioreg.Unregister(name); err != nil {
+				t.Fatal(err)
+			}
+		}()
+	}
+	f := newFakeBroker(t)
+	defer f.ln.Close()
+	u, err := url.Parse("tcp://alice:secret@" + f.ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := &jsonAPI{}
+	b := newMQTTBridge(j, &mqttOpts{broker: u, hostname: "host", topic: "periph/host", discovery: "homeassistant", interval: time.Hour})
+
+	c := f.accept()
+	if c.clientID != "periph-web-host" || c.user != "alice" || c.password != "secret" {
+		t.Fatalf("unexpected CONNECT %+v", c)
+	}
+	if c.will.topic != "periph/host/status" || string(c.will.payload) != "offline" || !c.will.retain {
+		t.Fatalf("unexpected will %+v", c.will)
+	}
+	if s := <-c.subscribed; s != "periph/host/gpio/+/set" {
+		t.Fatalf("unexpected subscription %q", s)
+	}
+	raw := c.waitFor(t, "homeassistant/switch/periph_host/MQTT1/config").payload
+	e := haEntity{}
+	if err = json.Unmarshal(raw, &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.CommandTopic != "periph/host/gpio/MQTT1/set" || e.StateTopic != "periph/host/gpio/MQTT1" || e.AvailabilityTopic != "periph/host/status" {
+		t.Fatalf("unexpected discovery %s", raw)
+	}
+	// An input can't be driven.
+	raw = c.waitFor(t, "homeassistant/binary_sensor/periph_host/MQTT2/config").payload
+	e = haEntity{}
+	if err = json.Unmarshal(raw, &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.CommandTopic != "" || e.StateTopic != "periph/host/gpio/MQTT2" {
+		t.Fatalf("unexpected discovery %s", raw)
+	}
+	if m := c.waitFor(t, "periph/host/status"); string(m.payload) != "online" {
+		t.Fatalf("unexpected status %q", m.payload)
+	}
+	if m := c.waitFor(t, "periph/host/gpio/MQTT2"); string(m.payload) != "1" {
+		t.Fatalf("unexpected level %+v", m)
+	}
+
+	// Drive the pin from the broker.
+	c.publish("periph/host/gpio/MQTT1/set", "ON", false)
+	if m := c.waitFor(t, "periph/host/gpio/MQTT1"); string(m.payload) != "1" || !m.retain {
+		t.Fatalf("unexpected level %+v", m)
+	}
+	if p.L != gpio.High {
+		t.Fatal("pin was not set")
+	}
+	c.publish("periph/host/gpio/MQTT1/set", "0", false)
+	if m := c.waitFor(t, "periph/host/gpio/MQTT1"); string(m.payload) != "0" {
+		t.Fatalf("unexpected level %+v", m)
+	}
+	if p.L != gpio.Low {
+		t.Fatal("pin was not set")
+	}
+	// A retained command is ignored, so the next level is the one of the
+	// following command.
+	c.publish("periph/host/gpio/MQTT1/set", "1", true)
+	c.publish("periph/host/gpio/MQTT1/set", "0", false)
+	if m := c.waitFor(t, "periph/host/gpio/MQTT1"); string(m.payload) != "0" {
+		t.Fatalf("unexpected level %+v", m)
+	}
+	if p.L != gpio.Low {
+		t.Fatal("retained command was applied")
+	}
+
+	b.close()
+	if m := c.waitFor(t, "periph/host/status"); string(m.payload) != "offline" {
+		t.Fatalf("unexpected status %q", m.payload)
+	}
+	select {
+	case <-c.disconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("DISCONNECT not received")
+	}
+}
+
+func TestMQTTClientID(t *testing.T) {
+	if id := mqttClientID("pi"); id != "periph-web-pi" {
+		t.Fatal(id)
+	}
+	a := mqttClientID("greenhouse-controller-1")
+	b := mqttClientID("greenhouse-controller-2")
+	if len(a) != 23 || len(b) != 23 || a == b {
+		t.Fatal(a, b)
+	}
+}
+
+func TestMQTTLength(t *testing.T) {
+	for _, l := range []int{0, 127, 128, 16383, 16384, 2097151, 2097152} {
+		b := append([]byte{mqttPingReq}, appendMQTTLength(nil, l)...)
+		b = append(b, make([]byte, l)...)
+		_, body, err := readMQTTPacket(bufio.NewReader(bytes.NewReader(b)))
+		if l > mqttMaxPacket {
+			if err == nil {
+				t.Fatalf("%d: expected error", l)
+			}
+			continue
+		}
+		if err != nil || len(body) != l {
+			t.Fatalf("%d: %d, %v", l, len(body), err)
+		}
+	}
+}
+
+// fakeBroker is a MQTT broker stand-in accepting a single client.
+type fakeBroker struct {
+	ln net.Listener
+}
+
+func newFakeBroker(t *testing.T) *fakeBroker {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &fakeBroker{ln: ln}
+}
+
+// fakeClient is a client connected to the fakeBroker.
+type fakeClient struct {
+	conn         net.Conn
+	clientID     string
+	user         string
+	password     string
+	will         mqttMessage
+	subscribed   chan string
+	published    chan *mqttMessage
+	disconnected chan struct{}
+}
+
+// accept accepts a client and processes its CONNECT.
+func (f *fakeBroker) accept() *fakeClient {
+	conn, err := f.ln.Accept()
+	if err != nil {
+		panic(err)
+	}
+	c := &fakeClient{
+		conn:         conn,
+		subscribed:   make(chan string, 16),
+		published:    make(chan *mqttMessage, 1024),
+		disconnected: make(chan struct{}),
+	}
+	r := bufio.NewReader(conn)
+	_, body, err := readMQTTPacket(r)
+	if err != nil {
+		panic(err)
+	}
+	// Skip the protocol name and level.
+	_, body, _ = readMQTTString(body)
+	flags := body[1]
+	body = body[4:]
+	c.clientID, body, _ = readMQTTString(body)
+	if flags&0x04 != 0 {
+		var s string
+		c.will.topic, body, _ = readMQTTString(body)
+		s, body, _ = readMQTTString(body)
+		c.will.payload = []byte(s)
+		c.will.retain = flags&0x20 != 0
+	}
+	if flags&0x80 != 0 {
+		c.user, body, _ = readMQTTString(body)
+	}
+	if flags&0x40 != 0 {
+		c.password, _, _ = readMQTTString(body)
+	}
+	c.write(mqttConnAck, []byte{0, 0})
+	go c.serve(r)
+	return c
+}
+
+func (c *fakeClient) serve(r *bufio.Reader) {
+	for {
+		t, body, err := readMQTTPacket(r)
+		if err != nil {
+			return
+		}
+		switch t & 0xF0 {
+		case mqttPublish:
+			m, _, err := parseMQTTPublish(t, body)
+			if err != nil {
+				panic(err)
+			}
+			c.published <- m
+		case mqttSubscribe:
+			id := body[:2]
+			codes := []byte{}
+			for rest := body[2:]; len(rest) != 0; rest = rest[1:] {
+				var f string
+				f, rest, _ = readMQTTString(rest)
+				c.subscribed <- f
+				codes = append(codes, 0)
+			}
+			c.write(mqttSubAck, append(append([]byte{}, id...), codes...))
+		case mqttPingReq:
+			c.write(mqttPingResp, nil)
+		case mqttDisconnect:
+			close(c.disconnected)
+			return
+		}
+	}
+}
+
+func (c *fakeClient) write(t byte, body []byte) {
+	b := append([]byte{t}, appendMQTTLength(nil, len(body))...)
+	if _, err := c.conn.Write(append(b, body...)); err != nil {
+		panic(err)
+	}
+}
+
+// publish sends a QoS 1 message to the client.
+func (c *fakeClient) publish(topic, payload string, retain bool) {
+	b := binary.BigEndian.AppendUint16(appendMQTTString(nil, topic), 1)
+	t := mqttPublish | 0x02
+	if retain {
+		t |= 0x01
+	}
+	c.write(byte(t), append(b, payload...))
+}
+
+// waitFor returns the next message published on topic, skipping the others.
+func (c *fakeClient) waitFor(t *testing.T, topic string) *mqttMessage {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case m := <-c.published:
+			if m.topic == topic {
+				return m
+			}
+		case <-timeout:
+			t.Fatalf("no message on %s", topic)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+
+	"periph.io/x/conn/v3/onewire"
+	"periph.io/x/conn/v3/onewire/onewirereg"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/devices/v3/ds18b20"
+)
+
+// ds18b20DefaultBits is the default resolution of a DS18B20 conversion. 10
+// bits is 0.25°C in 188ms, which is on par with the accuracy of the sensor.
+const ds18b20DefaultBits = 10
+
+// onewireFamilies are the family codes of common 1-Wire devices.
+var onewireFamilies = map[byte]string{
+	0x01: "DS2401",
+	0x05: "DS2405",
+	0x10: "DS18S20",
+	0x12: "DS2406",
+	0x1D: "DS2423",
+	0x20: "DS2450",
+	0x22: "DS1822",
+	0x23: "DS2433",
+	0x26: "DS2438",
+	0x28: "DS18B20",
+	0x29: "DS2408",
+	0x2D: "DS2431",
+	0x3A: "DS2413",
+	0x3B: "DS1825",
+	0x42: "DS28EA00",
+}
+
+// /api/periph/v1/onewire/list
+
+// onewireDevice is a device found on a 1-Wire bus.
+type onewireDevice struct {
+	// Addr is the 64 bits ROM code formatted in hexadecimal, since it doesn't
+	// fit in a JavaScript number.
+	Addr string
+	// Family is the low byte of the ROM code and FamilyName the device type it
+	// designates, if known.
+	Family     byte
+	FamilyName string
+	// Serial is the 48 bits serial number following the family code.
+	Serial string
+}
+
+type onewireRef struct {
+	Name    string
+	Aliases []string
+	Number  int
+	Err     string
+	Q       string
+	Devices []onewireDevice
+}
+
+// apiOneWireList lists the 1-Wire buses and searches them for devices.
+func (j *jsonAPI) apiOneWireList() ([]onewireRef, int) {
+	buses := onewirereg.All()
+	out := make([]onewireRef, 0, len(buses))
+	for _, ref := range buses {
+		h := onewireRef{Name: ref.Name, Aliases: ref.Aliases, Number: ref.Number, Devices: []onewireDevice{}}
+		bus, err := ref.Open()
+		if err != nil {
+			h.Err = err.Error()
+			out = append(out, h)
+			continue
+		}
+		if p, ok := bus.(onewire.Pins); ok {
+			h.Q = p.Q().Name()
+		}
+		addrs, err := bus.Search(false)
+		if err != nil {
+			if _, ok := err.(onewire.NoDevicesError); !ok {
+				h.Err = err.Error()
+			}
+		}
+		for _, a := range addrs {
+			h.Devices = append(h.Devices, newOneWireDevice(a))
+		}
+		_ = bus.Close()
+		out = append(out, h)
+	}
+	return out, 200
+}
+
+// newOneWireDevice decodes the ROM code a: family code, serial number and
+// CRC from the lowest to the highest byte.
+func newOneWireDevice(a onewire.Address) onewireDevice {
+	f := byte(a)
+	return onewireDevice{
+		Addr:       fmt.Sprintf("%#016x", uint64(a)),
+		Family:     f,
+		FamilyName: onewireFamilies[f],
+		Serial:     fmt.Sprintf("%012x", uint64(a>>8)&0xFFFFFFFFFFFF),
+	}
+}
+
+// /api/periph/v1/onewire/ds18b20/read
+
+type ds18b20ReadIn struct {
+	// Bus is the name, alias or number of the bus. All the buses are read
+	// when empty.
+	Bus string
+	// Bits is the resolution from 9 to 12 bits, 10 by default.
+	Bits int
+}
+
+// ds18b20Reading is the temperature of one sensor. When the bus fails, there
+// is a single reading with only Bus and Err set.
+type ds18b20Reading struct {
+	Bus     string
+	Addr    string
+	Family  string
+	Celsius float64
+	// Text is the temperature formatted by periph.
+	Text string
+	Err  string
+}
+
+// apiDS18B20Read starts a conversion on all the DS18B20 and DS18S20 sensors
+// of the buses at once and returns their temperatures. A bus without any of
+// them returns no reading.
+//
+// It returns 400 if the bus is not found and 409 if one of the buses read is
+// leased by another client.
+func (j *jsonAPI) apiDS18B20Read(c *caller, in *ds18b20ReadIn) ([]ds18b20Reading, int) {
+	bits := in.Bits
+	if bits == 0 {
+		bits = ds18b20DefaultBits
+	}
+	if bits < 9 || bits > 12 {
+		return []ds18b20Reading{{Bus: in.Bus, Err: "invalid resolution"}}, 200
+	}
+	var keys []string
+	if in.Bus == "" {
+		for _, ref := range onewirereg.All() {
+			keys = append(keys, "onewire:"+ref.Name)
+		}
+	} else {
+		k, err := onewireKey(in.Bus)
+		if err != nil {
+			return []ds18b20Reading{{Bus: in.Bus, Err: err.Error()}}, 400
+		}
+		keys = append(keys, k)
+	}
+	if err := j.leases.check(c, keys...); err != nil {
+		return []ds18b20Reading{{Bus: in.Bus, Err: err.Error()}}, 409
+	}
+	defer j.locks.lock(keys...)()
+	out := []ds18b20Reading{}
+	for _, ref := range onewirereg.All() {
+		if in.Bus == "" || keys[0] == "onewire:"+ref.Name {
+			out = append(out, readDS18B20(ref, bits)...)
+		}
+	}
+	return out, 200
+}
+
+// readDS18B20 reads the temperature sensors on the bus ref.
+func readDS18B20(ref *onewirereg.Ref, bits int) []ds18b20Reading {
+	bus, err := ref.Open()
+	if err != nil {
+		return []ds18b20Reading{{Bus: ref.Name, Err: err.Error()}}
+	}
+	defer bus.Close()
+	addrs, err := bus.Search(false)
+	if err != nil {
+		if _, ok := err.(onewire.NoDevicesError); ok {
+			return nil
+		}
+		return []ds18b20Reading{{Bus: ref.Name, Err: err.Error()}}
+	}
+	var out []ds18b20Reading
+	var devs []*ds18b20.Dev
+	for _, a := range addrs {
+		f := ds18b20.Family(a)
+		if f != ds18b20.DS18B20 && f != ds18b20.DS18S20 {
+			continue
+		}
+		r := ds18b20Reading{Bus: ref.Name, Addr: newOneWireDevice(a).Addr, Family: f.String()}
+		// New sets the resolution of the sensor.
+		d, err := ds18b20.New(bus, a, bits)
+		if err != nil {
+			r.Err = err.Error()
+		}
+		out = append(out, r)
+		devs = append(devs, d)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	if err = ds18b20.ConvertAll(bus, bits); err != nil {
+		return []ds18b20Reading{{Bus: ref.Name, Err: err.Error()}}
+	}
+	for i, d := range devs {
+		if d == nil {
+			continue
+		}
+		t, err := d.LastTemp()
+		if err != nil {
+			out[i].Err = err.Error()
+			continue
+		}
+		out[i].Celsius = float64(t-physic.ZeroCelsius) / float64(physic.Celsius)
+		out[i].Text = t.String()
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/binary"
+	"net/http"
+	"testing"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/onewire"
+	"periph.io/x/conn/v3/onewire/onewirereg"
+	"periph.io/x/conn/v3/onewire/onewiretest"
+)
+
+// TestWebDS18B20Read verifies how the buses are resolved and leased.
+func TestWebDS18B20Read(t *testing.T) {
+	f := registerFakes(t)
+	// A bus with a DS2401 serial number but no temperature sensor.
+	rom := []byte{0x01, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06}
+	other := onewire.Address(binary.LittleEndian.Uint64(append(rom, onewire.CalcCRC(rom))))
+	if err := onewirereg.Register("WEB1W2", nil, 98, func() (onewire.BusCloser, error) {
+		return &onewiretest.Playback{Ops: []onewiretest.IO{{W: []byte{0xF0}}}, Devices: []onewire.Address{other}, DontPanic: true, QPin: gpio.INVALID}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = onewirereg.Unregister("WEB1W2") })
+	ts := newTestServer(t, &webOpts{})
+
+	for _, name := range []string{"WEB1W2", "98"} {
+		var out []ds18b20Reading
+		ts.post(t, "/api/periph/v1/onewire/ds18b20/read", &ds18b20ReadIn{Bus: name}, &out, 200)
+		if out == nil || len(out) != 0 {
+			t.Fatalf("%s: %v", name, out)
+		}
+	}
+	var out []ds18b20Reading
+	ts.post(t, "/api/periph/v1/onewire/ds18b20/read", &ds18b20ReadIn{Bus: "NOPE"}, &out, 400)
+	if len(out) != 1 || out[0].Err == "" {
+		t.Fatal(out)
+	}
+
+	// Lease the bus by its number.
+	var l leaseOut
+	ts.post(t, "/api/periph/v1/lease/acquire", &leaseIn{OneWire: []string{"99"}}, &l, 200)
+	if l.ID == "" {
+		t.Fatal(l)
+	}
+	for _, name := range []string{"WEB1W", ""} {
+		out = nil
+		ts.post(t, "/api/periph/v1/onewire/ds18b20/read", &ds18b20ReadIn{Bus: name}, &out, 409)
+		if len(out) != 1 || out[0].Err == "" {
+			t.Fatalf("%q: %v", name, out)
+		}
+	}
+	// The other bus is not leased.
+	ts.post(t, "/api/periph/v1/onewire/ds18b20/read", &ds18b20ReadIn{Bus: "WEB1W2"}, &out, 200)
+	// The holder reads the sensor.
+	f.setOneWire(
+		onewiretest.IO{W: []byte{0xF0}},
+		onewiretest.IO{W: []byte{0x55, 0x28, 0xAC, 0x41, 0x0E, 0x07, 0x00, 0x00, 0x74, 0xBE}, R: []byte{0xE0, 0x01, 0x00, 0x00, 0x3F, 0xFF, 0x10, 0x10, 0x3F}},
+		onewiretest.IO{W: []byte{0xCC, 0x44}, Pull: onewire.StrongPullup},
+		onewiretest.IO{W: []byte{0x55, 0x28, 0xAC, 0x41, 0x0E, 0x07, 0x00, 0x00, 0x74, 0xBE}, R: []byte{0xE0, 0x01, 0x00, 0x00, 0x3F, 0xFF, 0x10, 0x10, 0x3F}},
+	)
+	ts.postWith(t, http.Header{leaseHeader: {l.ID}}, "/api/periph/v1/onewire/ds18b20/read", &ds18b20ReadIn{Bus: "WEB1W"}, &out, 200)
+	if len(out) != 1 || out[0].Err != "" || out[0].Celsius != 30 {
+		t.Fatal(out)
+	}
+	var leased []leaseInfo
+	ts.post(t, "/api/periph/v1/lease/list", map[string]string{}, &leased, 200)
+	if len(leased) != 1 || len(leased[0].OneWire) != 1 || leased[0].OneWire[0] != "WEB1W" {
+		t.Fatal(leased)
+	}
+}
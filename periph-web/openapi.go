@@ -0,0 +1,227 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// openAPIVersion is the version of the JSON API described by the document.
+const openAPIVersion = "1.0.0"
+
+var (
+	byteListType = reflect.TypeOf(byteList(nil))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// schema is an OpenAPI 3 schema object. Only the fields needed to describe
+// the JSON API are supported.
+type schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+}
+
+// openAPIGen generates schemas from Go types via reflection.
+type openAPIGen struct {
+	// schemas are the named schemas, one per Go struct type.
+	schemas map[string]*schema
+}
+
+// schemaOf returns the schema for t. Structs are stored as named schemas and
+// referenced.
+func (g *openAPIGen) schemaOf(t reflect.Type) *schema {
+	switch t {
+	case byteListType:
+		zero, max := 0, 255
+		return &schema{Type: "array", Nullable: true, Items: &schema{Type: "integer", Minimum: &zero, Maximum: &max}}
+	case timeType:
+		return &schema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := g.schemaOf(t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+		s.Nullable = true
+		return s
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64:
+		return &schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &schema{Type: "integer", Format: "int32"}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		zero := 0
+		return &schema{Type: "integer", Format: "int32", Minimum: &zero}
+	case reflect.Uint, reflect.Uint64:
+		zero := 0
+		return &schema{Type: "integer", Format: "int64", Minimum: &zero}
+	case reflect.Float32:
+		return &schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &schema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes []byte as base64.
+			return &schema{Type: "string", Format: "byte"}
+		}
+		return &schema{Type: "array", Nullable: true, Items: g.schemaOf(t.Elem())}
+	case reflect.Array:
+		return &schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &schema{Type: "object", Nullable: true, AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		ref := &schema{Ref: "#/components/schemas/" + name}
+		if _, ok := g.schemas[name]; ok {
+			return ref
+		}
+		s := &schema{Type: "object", Properties: map[string]*schema{}}
+		// Register before recursing in case the type is recursive.
+		g.schemas[name] = s
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			n := f.Name
+			if tag := f.Tag.Get("json"); tag != "" {
+				if tag == "-" {
+					continue
+				}
+				if t := strings.Split(tag, ",")[0]; t != "" {
+					n = t
+				}
+			}
+			s.Properties[n] = g.schemaOf(f.Type)
+		}
+		return ref
+	}
+	// interface{} and anything else.
+	return &schema{}
+}
+
+// genOpenAPI returns the OpenAPI 3 document describing the JSON API handlers.
+//
+// It uses the same reflection on the handler signatures as webServer.api().
+func genOpenAPI(hostname string, apis []apiHandler, auth *authenticator) ([]byte, error) {
+	g := openAPIGen{schemas: map[string]*schema{}}
+	paths := map[string]interface{}{}
+	for _, h := range apis {
+		t := reflect.TypeOf(h.fn)
+		op := map[string]interface{}{
+			"operationId": operationID(h.path),
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "Success",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": g.schemaOf(t.Out(0))},
+					},
+				},
+				"400": map[string]interface{}{"description": "Malformed request or invalid XSRF token"},
+				"413": map[string]interface{}{"description": "Request body too large"},
+			},
+		}
+		// Handlers that accept no argument still require an empty JSON object.
+		in := &schema{Type: "object"}
+		nArg := t.NumIn()
+		if nArg != 0 && t.In(0) == callerType {
+			nArg--
+			op["parameters"] = []map[string]interface{}{{
+				"name":        leaseHeader,
+				"in":          "header",
+				"description": "Lease ID returned by /api/periph/v1/lease/acquire",
+				"schema":      &schema{Type: "string"},
+			}}
+			op["responses"].(map[string]interface{})["409"] = map[string]interface{}{"description": "Resource leased by another client"}
+		}
+		if nArg == 1 {
+			in = g.schemaOf(t.In(t.NumIn() - 1))
+		}
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": in},
+			},
+		}
+		paths[h.path] = map[string]interface{}{"post": op}
+	}
+	// Each alternative requires the XSRF token, in the cookie or the header,
+	// and one of the authentication schemes, if any.
+	schemes := map[string]interface{}{
+		"xsrf":       map[string]string{"type": "apiKey", "in": "cookie", "name": "XSRF-TOKEN"},
+		"xsrfHeader": map[string]string{"type": "apiKey", "in": "header", "name": xsrfHeader},
+	}
+	var security []map[string][]string
+	for _, x := range []string{"xsrf", "xsrfHeader"} {
+		if auth == nil {
+			security = append(security, map[string][]string{x: {}})
+			continue
+		}
+		if len(auth.tokens) != 0 {
+			schemes["bearer"] = map[string]string{"type": "http", "scheme": "bearer"}
+			security = append(security, map[string][]string{x: {}, "bearer": {}})
+		}
+		if len(auth.users) != 0 {
+			schemes["basic"] = map[string]string{"type": "http", "scheme": "basic"}
+			security = append(security, map[string][]string{x: {}, "basic": {}})
+		}
+	}
+	doc := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "periph-web",
+			"description": "JSON API of periph-web running on " + hostname,
+			"version":     openAPIVersion,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas":         g.schemas,
+			"securitySchemes": schemes,
+		},
+		"security": security,
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// operationID returns a camelCase identifier for the path, e.g.
+// "/api/periph/v1/gpio/read" becomes "gpioRead".
+func operationID(path string) string {
+	out := ""
+	for _, p := range strings.FieldsFunc(strings.TrimPrefix(path, "/api/periph/v1/"), func(r rune) bool {
+		return r == '/' || r == '-' || r == '_' || r == '.'
+	}) {
+		if out == "" {
+			out = p
+		} else {
+			out += strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return out
+}
+
+// /api/periph/v1/openapi.json
+
+// getOpenAPI returns the OpenAPI document. It is a static document so it
+// doesn't require the XSRF token.
+func (s *webServer) getOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", cacheControlNone)
+	_, _ = w.Write(s.openAPI)
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/pin"
+)
+
+// pinState is the function of a GPIO at a point in time, so it can be
+// restored later.
+type pinState struct {
+	p     gpio.PinIO
+	in    bool
+	pull  gpio.Pull
+	out   bool
+	level gpio.Level
+}
+
+// snapshotPin returns the current state of p.
+//
+// The output level is derived from the pin function instead of reading the
+// pin, which could return the level of the line instead.
+func snapshotPin(p gpio.PinIO) pinState {
+	s := pinState{p: p}
+	switch pinDirection(p) {
+	case gpio.IN:
+		s.in = true
+		s.pull = p.Pull()
+	case gpio.OUT:
+		s.out = true
+		s.level = p.(pin.PinFunc).Func() == gpio.OUT_HIGH
+	}
+	return s
+}
+
+// restore sets the pin back to its snapshotted state. A pin that was neither
+// an input nor an output is halted.
+func (s *pinState) restore() error {
+	if s.in {
+		return s.p.In(s.pull, gpio.NoEdge)
+	}
+	if s.out {
+		return s.p.Out(s.level)
+	}
+	return s.p.Halt()
+}
+
+// pinTracker remembers the original state of the GPIOs modified via the API.
+type pinTracker struct {
+	mu   sync.Mutex
+	orig map[string]pinState
+}
+
+// touch must be called before modifying p. Only the state before the first
+// modification is kept.
+func (t *pinTracker) touch(p gpio.PinIO) {
+	p = realPin(p)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.orig[p.Name()]; ok {
+		return
+	}
+	if t.orig == nil {
+		t.orig = map[string]pinState{}
+	}
+	t.orig[p.Name()] = snapshotPin(p)
+}
+
+// take returns the original state of the pins modified so far, sorted by name,
+// and forgets them.
+func (t *pinTracker) take() []pinState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]pinState, 0, len(t.orig))
+	for _, s := range t.orig {
+		out = append(out, s)
+	}
+	t.orig = nil
+	sort.Slice(out, func(i, j int) bool { return out[i].p.Name() < out[j].p.Name() })
+	return out
+}
+
+// restorePins restores all the GPIOs modified via the API to their original
+// state. It is meant to be called on shutdown, after the web server is
+// stopped.
+//
+// The leases are closed first, so an expiring lease doesn't drive its pins
+// again afterward.
+func (j *jsonAPI) restorePins() error {
+	j.leases.close()
+	var err error
+	var names []string
+	var errs []error
+	for _, s := range j.pins.take() {
+		j.events.unwatch(s.p.Name())
+		e := s.restore()
+		names = append(names, s.p.Name())
+		errs = append(errs, e)
+		if e != nil {
+			log.Printf("Failed to restore %s: %v", s.p.Name(), e)
+			if err == nil {
+				err = fmt.Errorf("failed to restore %s: %v", s.p.Name(), e)
+			}
+		} else {
+			log.Printf("Restored %s", s.p.Name())
+		}
+	}
+	j.audit.recordRestore("shutdown", names, errs)
+	return err
+}
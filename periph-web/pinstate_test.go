@@ -0,0 +1,70 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpiotest"
+)
+
+func TestSnapshotPin(t *testing.T) {
+	for _, c := range []struct {
+		p    *gpiotest.Pin
+		want pinState
+	}{
+		{&gpiotest.Pin{N: "A", Fn: "In/High", P: gpio.PullUp}, pinState{in: true, pull: gpio.PullUp}},
+		{&gpiotest.Pin{N: "B", Fn: "Out/Low", L: gpio.High}, pinState{out: true, level: gpio.Low}},
+		{&gpiotest.Pin{N: "C", Fn: "Out/High"}, pinState{out: true, level: gpio.High}},
+		{&gpiotest.Pin{N: "D", Fn: "I2C1_SDA"}, pinState{}},
+	} {
+		c.want.p = c.p
+		if s := snapshotPin(c.p); s != c.want {
+			t.Errorf("%s: %+v", c.p.N, s)
+		}
+	}
+}
+
+// TestWebRestorePins verifies that the pins driven during the session are
+// restored to their original state on shutdown.
+func TestWebRestorePins(t *testing.T) {
+	f := registerFakes(t)
+	f.pins[0].P = gpio.PullDown
+	ts := newTestServer(t, &webOpts{})
+	var out []string
+	ts.post(t, "/api/periph/v1/gpio/in", []pinIn{{Name: "WEB_ALIAS", Pull: "up"}}, &out, 200)
+	ts.post(t, "/api/periph/v1/gpio/out", map[string]bool{"WEB2": true}, &out, 200)
+	if f.pins[0].P != gpio.PullUp || f.pins[1].L != gpio.High {
+		t.Fatal("pins were not modified")
+	}
+	if err := ts.s.apis.restorePins(); err != nil {
+		t.Fatal(err)
+	}
+	if f.pins[0].P != gpio.PullDown || f.pins[1].L != gpio.Low {
+		t.Fatalf("pins were not restored: %s %s", f.pins[0].P, f.pins[1].L)
+	}
+}
+
+// TestWebRestorePinsLease verifies that a lease can't expire and drive its
+// pins once they were restored on shutdown.
+func TestWebRestorePinsLease(t *testing.T) {
+	f := registerFakes(t)
+	ts := newTestServer(t, &webOpts{})
+	j := &ts.s.apis
+	if _, err := j.leases.acquire(&caller{user: "test"}, &leaseIn{Pins: []string{"WEB2"}}, 10*time.Millisecond, j.expireLease); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.restorePins(); err != nil {
+		t.Fatal(err)
+	}
+	// Driven by something else than the server after it stopped.
+	f.pins[1].L = gpio.High
+	time.Sleep(50 * time.Millisecond)
+	if f.pins[1].L != gpio.High {
+		t.Fatal("the expired lease restored its pin")
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/conn/v3/i2c"
+)
+
+// Limits of a sequence, so a single request can't hog the hardware.
+const (
+	maxSequenceSteps = 1000
+	// maxSequenceWait is the maximum sum of the sleep durations and the edge
+	// timeouts of a sequence.
+	maxSequenceWait = 10 * time.Second
+	// defaultEdgeTimeout is the default timeout of a gpio/wait-edge step.
+	defaultEdgeTimeout = time.Second
+)
+
+// /api/periph/v1/sequence
+
+// sequenceStep is a single step of a sequence. Op selects the fields used:
+//   - "gpio/out": sets Pin as output at Level.
+//   - "gpio/in": sets Pin as input with Pull and Edge, like
+//     /api/periph/v1/gpio/in. The edges are not streamed to
+//     /raw/periph/v1/gpio/events so they can be waited for by the next steps.
+//   - "gpio/read": reads the level of Pin.
+//   - "gpio/wait-edge": waits up to Timeout for an edge on Pin, which must have
+//     been set as input with an edge.
+//   - "sleep": sleeps for Duration.
+//   - "i2c/tx": runs the I²C transaction I2C, like /api/periph/v1/i2c/tx.
+//   - "spi/tx": runs the SPI transaction SPI, like /api/periph/v1/spi/tx.
+//
+// Timeout and Duration are parsed with time.ParseDuration, e.g. "10ms".
+type sequenceStep struct {
+	Op       string
+	Pin      string
+	Level    bool
+	Pull     string
+	Edge     string
+	Timeout  string
+	Duration string
+	I2C      *i2cTx
+	SPI      *spiTx
+}
+
+// stepResult is the result of a step that was run.
+type stepResult struct {
+	// Start is when the step started relative to the start of the sequence
+	// and Duration how long it took, both in nanoseconds.
+	Start    time.Duration
+	Duration time.Duration
+	// Level is the level read by "gpio/read" and "gpio/wait-edge", as 0 or 1.
+	Level int
+	// Edge is true if "gpio/wait-edge" detected an edge before the timeout.
+	Edge bool
+	// R is the data read by "i2c/tx" and "spi/tx" and Packets the data read
+	// by the packets of "spi/tx".
+	R       byteList
+	Packets []byteList
+	Err     string
+}
+
+type sequenceOut struct {
+	// Steps are the results of the steps that were run. The sequence stops at
+	// the first step that fails.
+	Steps []stepResult
+	Err   string
+}
+
+// seqStep is a validated sequenceStep.
+type seqStep struct {
+	*sequenceStep
+	p gpio.PinIO
+	d time.Duration
+}
+
+// apiSequence validates the steps then runs them back to back on the server,
+// without the latency of one HTTP request per step.
+//
+// Nothing is run if a step is invalid. It returns 400 if one of the buses is
+// not found and 409 if one of the pins or buses is leased by another client.
+// The sequences are serialized, so the steps of two sequences never
+// interleave, and the other calls using the same pins or buses wait for the
+// sequence to complete.
+func (j *jsonAPI) apiSequence(c *caller, in []sequenceStep) (out *sequenceOut, status int) {
+	defer func() { j.audit.record(c, in, out, status) }()
+	steps, keys, err := parseSequence(in)
+	if err != nil {
+		status = 200
+		if errors.As(err, new(*unknownBusError)) {
+			status = 400
+		}
+		return &sequenceOut{Steps: []stepResult{}, Err: err.Error()}, status
+	}
+	if err = j.leases.check(c, keys...); err != nil {
+		return &sequenceOut{Steps: []stepResult{}, Err: err.Error()}, 409
+	}
+	j.sequence.Lock()
+	defer j.sequence.Unlock()
+	defer j.locks.lock(keys...)()
+	buses := map[string]i2c.BusCloser{}
+	defer func() {
+		for _, b := range buses {
+			_ = b.Close()
+		}
+	}()
+	out = &sequenceOut{Steps: make([]stepResult, 0, len(steps))}
+	start := time.Now()
+	for i := range steps {
+		s := &steps[i]
+		begin := time.Now()
+		r := j.runStep(buses, s)
+		r.Start = begin.Sub(start)
+		r.Duration = time.Since(begin)
+		out.Steps = append(out.Steps, r)
+		if r.Err != "" {
+			out.Err = fmt.Sprintf("step %d: %s", i, r.Err)
+			break
+		}
+	}
+	return out, 200
+}
+
+// parseSequence validates the steps and returns the lease keys of the
+// resources they use.
+func parseSequence(in []sequenceStep) ([]seqStep, []string, error) {
+	if len(in) > maxSequenceSteps {
+		return nil, nil, fmt.Errorf("too many steps; max %d", maxSequenceSteps)
+	}
+	var keys []string
+	var wait time.Duration
+	out := make([]seqStep, len(in))
+	for i := range in {
+		s := &out[i]
+		s.sequenceStep = &in[i]
+		var err error
+		switch s.Op {
+		case "gpio/out", "gpio/in", "gpio/read":
+		case "gpio/wait-edge":
+			s.d = defaultEdgeTimeout
+			if s.Timeout != "" {
+				s.d, err = time.ParseDuration(s.Timeout)
+			}
+		case "sleep":
+			s.d, err = time.ParseDuration(s.Duration)
+		case "i2c/tx":
+			if s.I2C == nil {
+				err = errors.New("missing I2C")
+			} else {
+				var k string
+				if k, err = i2cKey(s.I2C.Bus); err == nil {
+					keys = append(keys, k)
+				}
+			}
+		case "spi/tx":
+			if s.SPI == nil {
+				err = errors.New("missing SPI")
+			} else {
+				var k string
+				if k, err = spiKey(s.SPI.Port); err == nil {
+					keys = append(keys, k)
+				}
+			}
+		default:
+			err = fmt.Errorf("invalid op %q", s.Op)
+		}
+		if err == nil && s.d < 0 {
+			err = errors.New("negative duration")
+		}
+		if err == nil && strings.HasPrefix(s.Op, "gpio/") {
+			if s.p = gpioreg.ByName(s.Pin); s.p == nil {
+				err = errors.New("pin not found")
+			} else {
+				keys = append(keys, pinKey(s.p))
+			}
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("step %d: %w", i, err)
+		}
+		if wait += s.d; wait > maxSequenceWait {
+			return nil, nil, fmt.Errorf("sleeps and timeouts exceed %s", maxSequenceWait)
+		}
+	}
+	return out, keys, nil
+}
+
+// runStep runs a validated step.
+func (j *jsonAPI) runStep(buses map[string]i2c.BusCloser, s *seqStep) stepResult {
+	var r stepResult
+	var err error
+	switch s.Op {
+	case "gpio/out":
+		err = j.gpioOut(s.p, gpio.Level(s.Level))
+	case "gpio/in":
+		err = j.gpioIn(s.p, &pinIn{Name: s.Pin, Pull: s.Pull, Edge: s.Edge}, false)
+	case "gpio/read":
+		r.Level = levelInt(s.p.Read())
+	case "gpio/wait-edge":
+		// Stop streaming the edges so they are not consumed concurrently.
+		j.events.unwatch(s.p.Name())
+		r.Edge = s.p.WaitForEdge(s.d)
+		r.Level = levelInt(s.p.Read())
+	case "sleep":
+		time.Sleep(s.d)
+	case "i2c/tx":
+		t := runI2CTx(buses, s.I2C)
+		r.R, r.Err = t.R, t.Err
+	case "spi/tx":
+		t := runSPITx(s.SPI)
+		r.R, r.Packets, r.Err = t.R, t.Packets, t.Err
+	}
+	if err != nil {
+		r.Err = err.Error()
+	}
+	return r
+}
+
+func levelInt(l gpio.Level) int {
+	if l {
+		return 1
+	}
+	return 0
+}
@@ -12,6 +12,6 @@ func getContent(path string) []byte {
 }
 
 var staticContent = map[string][]byte{
-	"static/index.html":  []byte("<!doctype html><meta charset=utf-8><meta name=viewport content=\"width=device-width,initial-scale=1\"><meta name=apple-mobile-web-app-capable content=\"yes\"><meta name=apple-mobile-web-app-status-bar-style content=\"blue\"><meta name=apple-mobile-web-app-capable content=\"yes\"><meta name=mobile-web-app-capable content=\"yes\"><meta name=description content=\"Periph web UI\"><meta name=author content=\"Periph Authors\"><title>periph-web</title><style>*{font-family:sans-serif;font-size:14px}h1{font-size:24px}h2{font-size:20px}h3{font-size:16px}h1,h2,h3{margin-bottom:.2em;margin-top:.2em}.err{background:#f44;border:1px solid #888;border-radius:10px;padding:10px;display:none}@media only screen and (max-width:500px){*{font-size:12px}}</style><script>\"use strict\";function log(){}class EventSource{constructor(){this._triggers={}}addEventListener(e,t,n){this._triggers[e]||(this._triggers[e]=[]);let s=n||{},o={capture:s.capture,listener:t,once:s.once,passive:s.passive};this._triggers[e].push(o)}removeEventListener(e,t,n){if(!this._triggers[e])return;let s=this._triggers[e].slice(),o=n||{};for(let t=s.length;t>0;t--){let n=s[t-1];n.callback===callback&&n.capture===o.capture&&n.passive===o.passive&&this._triggers[e].pop(t)}}dispatchEvent(e,t){log(\"dispatchEvent(\"+e+\", \"+t+\")\");let n=this._triggers[e];if(!n)return;let s=[];for(let e=0;e<n.length;e++){let o=n[e];o.listener.call(t),o.once&&s.push(o)}for(let e=0;e<s.length;e++)for(let t=0;t<n.length;n++)if(n[t]===s[e]){n.pop(t);break}}}function postJSON(e,t,n){function s(e){if(e.status==401)throw new Error(\"Please refresh the page\");if(e.status>=200&&e.status<300)return e.json();throw new Error(e.statusText)}function o(e,t){console.log(t),alertError(e+\": \"+t.toString())}let i={body:JSON.stringify(t),credentials:\"same-origin\",headers:{\"Content-Type\":\"application/json; charset=utf-8\"},method:\"POST\"};fetch(e,i).then(s).then(n).catch(t=>o(e,t))}function alertError(e){let t=document.getElementById(\"err\");t.innerText&&(t.innerText=t.innerText+`\n`),t.innerText=t.innerText+e+`\n`,t.style.display=\"block\"}class Pin{constructor(e,t,n,s){this.name=e,this.number=t,this._func=n,this.gpio=s}get func(){return this.gpio?this.gpio.func:this._func}}class GPIO{constructor(e,t,n){this.name=e,this.number=t,this.func=n,this._value=null}get value(){return this._value}onFuncUpdate(e,t){return this.func!==this._makeFunc(e,t)&&(this._value=t,this.func=this._makeFunc(e,t),!0)}onValueRead(e){return this._value!==e&&this.type!==\"out\"&&(this._value=e,this.func=this._makeFunc(this.type,e),!0)}get type(){return this.func.startsWith(\"Out/\")?\"out\":this.func.startsWith(\"In/\")?\"in\":this.func}_makeFunc(e,t){return e==\"in\"?t===!0?\"In/High\":t===!1?\"In/Low\":\"In/Ind\":e==\"out\"?t===!0?\"Out/High\":t===!1?\"Out/Low\":\"Out/Ind\":e}}class Header{constructor(e,t){this.name=e,this.pins=t}}var Controller=new class{constructor(){this.eventGPIO=new EventSource,this.eventDone=new EventSource,this.gpios={},this.headers={},this._polling={},this._pollingID=null,this._pollingRate=100,document.addEventListener(\"DOMContentLoaded\",()=>{this._fetchGPIO(),this._fetchHeader()},{once:!0})}setGPIOIn(e){log(\"setGPIOIn(\"+e.name+\")\");let t={Name:e.name,Pull:\"\",Edge:\"\"};e.onFuncUpdate(\"in\",null),this.eventGPIO.dispatchEvent(e.name,\"in\"),postJSON(\"/api/periph/v1/gpio/in\",[t],e=>{if(e[0]){alertError(e[0]);return}})}setGPIOOut(e,t){log(\"setGPIOOut(\"+e.name+\", \"+t+\")\");let n={};n[e.name]=t,e.onFuncUpdate(\"out\",t),this.eventGPIO.dispatchEvent(e.name,\"out\"),postJSON(\"/api/periph/v1/gpio/out\",n,e=>{if(e[0]){alertError(e[0]);return}})}_fetchGPIO(){postJSON(\"/api/periph/v1/gpio/list\",{},e=>{log(\"/api/periph/v1/gpio/list\");for(let t=0;t<e.length;t++){let n=e[t].Name,s=new GPIO(n,e[t].Number,e[t].Func);this.gpios[n]=s,this.eventGPIO.dispatchEvent(n)}this.eventDone.dispatchEvent(\"gpio\")})}_fetchHeader(){postJSON(\"/api/periph/v1/header/list\",{},e=>{log(\"/api/periph/v1/header/list\");for(let t in e){let n=[];for(let s=0;s<e[t].Pins.length;s++){let o=e[t].Pins[s],i=[];for(let t=0;t<o.length;t++){let n=o[t],e=new Pin(n.Name,n.Number,n.Func,this.gpios[n.Name]);e.gpio?(this._autoPoll(e.gpio),this.eventGPIO.addEventListener(e.gpio.name,()=>{this._autoPoll(e.gpio)})):this.eventGPIO.addEventListener(e.name,()=>{e.gpio=this.gpios[e.name],this._autoPoll(e.gpio),this.eventGPIO.addEventListener(e.gpio.name,()=>{this._autoPoll(e.gpio)})},{once:!0}),i[t]=e}n[s]=i}this.headers[t]=new Header(t,n)}this.eventDone.dispatchEvent(\"header\")})}_autoPoll(e){e.type==\"in\"?(log(\"Now polling \"+e.name),this._polling[e.name]=!0,this._pollingID===null&&(this._pollingID=window.setTimeout(this._refreshGPIO.bind(this),this._pollingRate))):(log(\"Stop polling \"+e.name),delete this._polling[e.name],this._pollingID&&!this._polling.length&&(window.clearTimeout(this._pollingID),this._pollingID=null))}_refreshGPIO(){let e=Object.keys(this._polling).sort();postJSON(\"/api/periph/v1/gpio/read\",e,t=>{for(let s=0;s<e.length;s++){let n=this.gpios[e[s]];switch(t[s]){case 0:n.onValueRead(!1)&&this.eventGPIO.dispatchEvent(n.name,!1);break;case 1:n.onValueRead(!0)&&this.eventGPIO.dispatchEvent(n.name,!0);break;default:n.onValueRead(null)&&this.eventGPIO.dispatchEvent(n.name,null);break}}this._pollingID=setTimeout(this._refreshGPIO.bind(this),this._pollingRate)})}};function fetchI2C(){postJSON(\"/api/periph/v1/i2c/list\",{},e=>{let t=document.getElementById(\"section-i2c\");for(let n=0;n<e.length;n++){let s=t.appendChild(document.createElement(\"i2c-elem\"));s.setupI2C(e[n].Name,e[n].Number,e[n].Err,e[n].SCL,e[n].SDA)}})}function fetchSPI(){postJSON(\"/api/periph/v1/spi/list\",{},e=>{let t=document.getElementById(\"section-spi\");for(let n=0;n<e.length;n++){let s=t.appendChild(document.createElement(\"spi-elem\"));s.setupSPI(e[n].Name,e[n].Number,e[n].Err,e[n].CLK,e[n].MOSI,e[n].MISO,e[n].CS)}})}function fetchState(){postJSON(\"/api/periph/v1/server/state\",{},e=>{document.title=\"periph-web - \"+e.Hostname;let t=document.getElementById(\"section-drivers-loaded\");if(e.State.Loaded.length){t.setupDrivers([\"Drivers loaded\"]);for(let n=0;n<e.State.Loaded.length;n++)t.appendRow([e.State.Loaded[n]])}else t.display=\"hidden\";if(t=document.getElementById(\"section-drivers-skipped\"),e.State.Skipped.length){t.setupDrivers([\"Drivers skipped\",\"Reason\"]);for(let n=0;n<e.State.Skipped.length;n++)t.appendRow([e.State.Skipped[n].D,e.State.Skipped[n].Err])}else t.display=\"hidden\";if(t=document.getElementById(\"section-drivers-failed\"),e.State.Failed.length){t.setupDrivers([\"Drivers failed\",\"Error\"]);for(let n=0;n<e.State.Failed.length;n++)t.appendRow([e.State.Failed[n].D,e.State.Failed[n].Err])}else t.display=\"hidden\"})}Controller.eventDone.addEventListener(\"header\",()=>{let e=document.getElementById(\"section-gpio\");Object.keys(Controller.headers).sort().forEach(t=>{e.appendChild(document.createElement(\"header-view\")).setupHeader(t)})},{once:!0}),document.addEventListener(\"DOMContentLoaded\",()=>{fetchI2C(),fetchSPI(),fetchState()},{once:!0});class HTMLElementTemplate extends HTMLElement{constructor(e){super();let t=document.querySelector(\"template#\"+e);this.attachShadow({mode:\"open\"}).appendChild(t.content.cloneNode(!0))}static get observedAttributes(){return[]}emitEvent(e,t){this.dispatchEvent(new CustomEvent(e,{detail:t,bubbles:!0}))}}</script><template id=template-data-table-elem><style>th{background-color:#4caf50;color:#fff}th,td{padding:.5rem;border-bottom:1px solid #ddd}tr:hover{background-color:#ccc}tr:nth-child(even):not(:hover){background:#f5f5f5}.inline{display:inline-block;margin-bottom:1rem;margin-right:2rem;vertical-align:top}</style><div class=inline><table><thead><tbody></table></div></template><script>\"use strict\";window.customElements.define(\"data-table-elem\",class extends HTMLElementTemplate{constructor(){super(\"template-data-table-elem\")}setupTable(e){let t=this.shadowRoot.querySelector(\"thead\");for(let n=0;n<e.length;n++)t.appendChild(document.createElement(\"th\")).innerText=e[n]}appendRow(e){let n=this.shadowRoot.querySelector(\"tbody\").appendChild(document.createElement(\"tr\")),t=[];for(let s=0;s<e.length;s++){let o=n.appendChild(document.createElement(\"td\"));e[s]instanceof Element?(o.appendChild(e[s]),t[s]=e[s]):(o.innerText=e[s],t[s]=o)}return t}})</script><template id=template-checkout-elem><style>@keyframes popIn{0%{transform:scale(1,1)}25%{transform:scale(1.2,1)}50%{transform:scale(1.4,1)}100%{transform:scale(1,1)}}@keyframes popOut{0%{transform:scale(1,1)}25%{transform:scale(1.2,1)}50%{transform:scale(1.4,1)}100%{transform:scale(1,1)}}div{display:inline-block;height:20px;position:relative;vertical-align:bottom}input{bottom:0;cursor:pointer;display:block;height:0%;left:0;margin:0;opacity:0;position:absolute;right:0;top:0;width:0%}span{cursor:pointer;margin-left:.25em;padding-left:40px;user-select:none}span:before{background:rgba(100,100,100,.2);border-radius:20px;box-shadow:inset 0 0 5px rgba(0,0,0,.8);content:\"\";display:inline-block;height:20px;left:0;position:absolute;transition:background .2s ease-out;width:40px}span:after{background-clip:padding-box;background:#fff;border-radius:20px;border:solid green 2px;content:\"\";display:block;font-weight:700;height:20px;left:-2px;position:absolute;text-align:center;top:-2px;transition:margin-left .1s ease-in-out;width:20px}input:checked+span:after{margin-left:20px}input:checked+span:before{transition:background .2s ease-in}input:not(:checked)+span:after{animation:popOut ease-in .3s normal}input:checked+span:after{animation:popIn ease-in .3s normal;background-clip:padding-box;margin-left:20px}input:checked+span:before{background:#20c997}input:disabled+span:before{box-shadow:0 0 black}input:disabled+span{color:#adb5bd}input:disabled:checked+span:before{background:#adb5bd}input:indeterminate+span:after{margin-left:10px}input:focus+span:before{outline:solid #cce5ff 2px}</style><div><label><input type=checkbox><span><slot></slot></span></label></div></template><script>\"use strict\";window.customElements.define(\"checkout-elem\",class extends HTMLElementTemplate{constructor(){super(\"template-checkout-elem\")}connectedCallback(){this.contentElem=this.shadowRoot.querySelector(\"span\"),this.checkboxElem=this.shadowRoot.querySelector(\"input\"),this.checkboxElem.addEventListener(\"click\",e=>{this.emitEvent(\"change\",{})},{passive:!0})}get checked(){return this.checkboxElem.checked}set checked(e){this.checkboxElem.checked=e}get disabled(){return this.checkboxElem.disabled}set disabled(e){this.checkboxElem.disabled=e}get indeterminate(){return this.checkboxElem.indeterminate}set indeterminate(e){this.checkboxElem.indeterminate=e}get text(){return this.contentElem.innerText}set text(e){this.contentElem.innerText=e}})</script><template id=template-drivers-elem><style>.inline{display:inline-block}</style><div class=inline><data-table-elem></data-table-elem></div></template><script>\"use strict\";window.customElements.define(\"drivers-elem\",class extends HTMLElementTemplate{constructor(){super(\"template-drivers-elem\")}setupDrivers(e){this.shadowRoot.querySelector(\"data-table-elem\").setupTable(e)}appendRow(e){this.shadowRoot.querySelector(\"data-table-elem\").appendRow(e)}})</script><template id=template-gpio-view><style>div{background:#ccc;border:1px solid #888;border-radius:10px;padding:10px}.gpio{background:#fcf}.controls{display:none}.box{border:1px solid #888;border-radius:6px;padding:3px}#func{display:none;background-color:#ccc;padding-bottom:3px;padding-right:3px;border-radius:3px}</style><div><span id=name>L</span>\n<span><span class=controls><span><checkout-elem id=io>I/O</checkout-elem>\n<checkout-elem id=level>Level</checkout-elem>\n</span></span><span id=func></span></span></div></template><script>\"use strict\";window.customElements.define(\"gpio-view\",class extends HTMLElementTemplate{constructor(){super(\"template-gpio-view\")}connectedCallback(){this.funcElem=this.shadowRoot.getElementById(\"func\"),this.ioElem=this.shadowRoot.getElementById(\"io\"),this.levelElem=this.shadowRoot.getElementById(\"level\"),this.ioElem.addEventListener(\"change\",e=>{log(this.id+\".io.change(\"+this.ioElem.checked+\")\"),this.ioElem.checked?Controller.setGPIOOut(this.pin.gpio,this.levelElem.checked):Controller.setGPIOIn(this.pin.gpio)},{passive:!0}),this.levelElem.addEventListener(\"change\",e=>{log(this.id+\".level.change(\"+this.levelElem.checked+\")\"),this.ioElem.checked&&Controller.setGPIOOut(this.pin.gpio,this.levelElem.checked)},{passive:!0})}setupPin(e){if(this.pin=e,this.id=this.pin.name,this.shadowRoot.getElementById(\"name\").textContent=this.pin.name,this.pin.gpio){log(this.id+\" is GPIO\"),this._isGPIO();return}log(this.id+\" is indeterminate\"),this.pin.func&&(this.funcElem.textContent=this.pin.func,this.funcElem.style.display=\"inline-block\"),Controller.eventGPIO.addEventListener(this.pin.name,()=>{log(this.id+\" is GPIO (late)\"),this._isGPIO()},{once:!0})}_isGPIO(){Controller.eventGPIO.addEventListener(this.pin.name,()=>this._gpioUpdate()),this.shadowRoot.querySelector(\"div\").classList.add(\"gpio\"),this._gpioUpdate()}_gpioUpdate(){log(this.id+\"._gpioUpdate(\"+this.pin.func+\")\"),this.pin.func.startsWith(\"In/\")||this.pin.func.startsWith(\"Out/\")?(this.funcElem.textContent=\"\",this.funcElem.style.display=\"none\",this.shadowRoot.querySelector(\".controls\").style.display=\"inline-block\",this.ioElem.checked=this.pin.func.startsWith(\"Out/\"),this.ioElem.disabled=!1,this.ioElem.indeterminate=!1,this.levelElem.checked=this.pin.func.endsWith(\"/High\"),this.levelElem.disabled=!this.ioElem.checked,this.ioElem.checked?this.ioElem.text=\"Out\":this.ioElem.text=\"In\",this.levelElem.checked?(this.levelElem.text=\"High\",this.levelElem.indeterminate=!1):this.pin.func.endsWith(\"/Low\")?(this.levelElem.text=\"Low\",this.levelElem.indeterminate=!1):(this.levelElem.indeterminate=!0,this.levelElem.text=\"Ind\")):(this.pin.func?(this.funcElem.textContent=this.pin.func,this.funcElem.style.display=\"inline-block\"):(this.funcElem.textContent=\"\",this.funcElem.style.display=\"none\"),this.ioElem.checked=!1,this.ioElem.disabled=!0,this.ioElem.indeterminate=!0,this.ioElem.text=\"I/O\",this.levelElem.checked=!1,this.levelElem.disabled=!0,this.levelElem.indeterminate=!0,this.levelElem.text=\"Level\")}})</script><template id=template-header-view><data-table-elem></data-table-elem></template><script>\"use strict\";window.customElements.define(\"header-view\",class extends HTMLElementTemplate{constructor(){super(\"template-header-view\")}setupHeader(e){this.header=Controller.headers[e];let t=this.shadowRoot.querySelector(\"data-table-elem\"),n=1;this.header.pins&&(n=this.header.pins[0].length);let s=[this.header.name];for(let e=1;e<n;e++)s[e]=\"\";t.setupTable(s);for(let n=0;n<this.header.pins.length;n++){let s=this.header.pins[n],e=[];for(let t=0;t<s.length;t++)e[t]=document.createElement(\"gpio-view\");e=t.appendRow(e);for(let t=0;t<e.length;t++)e[t].setupPin(s[t])}}})</script><template id=template-i2c-elem><data-table-elem></data-table-elem></template><script>\"use strict\";window.customElements.define(\"i2c-elem\",class extends HTMLElementTemplate{constructor(){super(\"template-i2c-elem\")}setupI2C(e,t,n,s,o){let i=this.shadowRoot.querySelector(\"data-table-elem\");i.setupTable([e,\"\"]),t!=-1&&i.appendRow([\"Number\",t]),n&&i.appendRow([\"Error\",n]),s&&i.appendRow([\"SCL\",s]),o&&i.appendRow([\"SDA\",o])}})</script><template id=template-spi-elem><data-table-elem></data-table-elem></template><script>\"use strict\";window.customElements.define(\"spi-elem\",class extends HTMLElementTemplate{constructor(){super(\"template-spi-elem\")}setupSPI(e,t,n,s,o,i,a){let r=this.shadowRoot.querySelector(\"data-table-elem\");r.setupTable([e,\"\"]),t!=-1&&r.appendRow([\"Number\",t]),n&&r.appendRow([\"Error\",n]),s&&r.appendRow([\"CLK\",s]),o&&r.appendRow([\"MOSI\",o]),o&&r.appendRow([\"MISO\",i]),a&&r.appendRow([\"CS\",a])}})</script><div class=err id=err></div><h1>GPIO</h1><div id=section-gpio></div><div id=section-state><h1>periph's state</h1><div><drivers-elem id=section-drivers-loaded></drivers-elem><drivers-elem id=section-drivers-skipped></drivers-elem><drivers-elem id=section-drivers-failed></drivers-elem></div></div><h1>I²C</h1><div id=section-i2c></div><h1>SPI</h1><div id=section-spi></div>"),
+	"static/index.html":  []byte("<!doctype html><meta charset=utf-8><meta name=viewport content=\"width=device-width,initial-scale=1\"><meta name=apple-mobile-web-app-capable content=\"yes\"><meta name=apple-mobile-web-app-status-bar-style content=\"blue\"><meta name=apple-mobile-web-app-capable content=\"yes\"><meta name=mobile-web-app-capable content=\"yes\"><meta name=description content=\"Periph web UI\"><meta name=author content=\"Periph Authors\"><title>periph-web</title><style>*{font-family:sans-serif;font-size:14px}h1{font-size:24px}h2{font-size:20px}h3{font-size:16px}h1,h2,h3{margin-bottom:.2em;margin-top:.2em}.err{background:#f44;border:1px solid #888;border-radius:10px;padding:10px;display:none}@media only screen and (max-width:500px){*{font-size:12px}}</style><script>\"use strict\";function log(){}class EventSource{constructor(){this._triggers={}}addEventListener(e,t,n){this._triggers[e]||(this._triggers[e]=[]);let s=n||{},o={capture:s.capture,listener:t,once:s.once,passive:s.passive};this._triggers[e].push(o)}removeEventListener(e,t,n){if(!this._triggers[e])return;let s=this._triggers[e].slice(),o=n||{};for(let t=s.length;t>0;t--){let n=s[t-1];n.callback===callback&&n.capture===o.capture&&n.passive===o.passive&&this._triggers[e].pop(t)}}dispatchEvent(e,t){log(\"dispatchEvent(\"+e+\", \"+t+\")\");let n=this._triggers[e];if(!n)return;let s=[];for(let e=0;e<n.length;e++){let o=n[e];o.listener.call(t),o.once&&s.push(o)}for(let e=0;e<s.length;e++)for(let t=0;t<n.length;n++)if(n[t]===s[e]){n.pop(t);break}}}function checkJSONStatus(e){if(e.status==401)throw new Error(\"Please refresh the page\");if(e.status==409)return e.json().then(e=>{throw new Error(\"Conflict: \"+JSON.stringify(e))});if(e.status>=200&&e.status<300)return e.json();throw new Error(e.statusText)}function onFetchError(e,t){console.log(t),alertError(e+\": \"+t.toString())}function postJSON(e,t,n){let s={body:JSON.stringify(t),credentials:\"same-origin\",headers:{\"Content-Type\":\"application/json; charset=utf-8\"},method:\"POST\"};fetch(e,s).then(checkJSONStatus).then(n).catch(t=>onFetchError(e,t))}function getJSON(e,t){fetch(e,{credentials:\"same-origin\"}).then(checkJSONStatus).then(t).catch(t=>onFetchError(e,t))}function alertError(e){let t=document.getElementById(\"err\");t.innerText&&(t.innerText=t.innerText+`\n`),t.innerText=t.innerText+e+`\n`,t.style.display=\"block\"}class Pin{constructor(e,t,n,s){this.name=e,this.number=t,this._func=n,this.gpio=s}get func(){return this.gpio?this.gpio.func:this._func}}class GPIO{constructor(e,t,n,s){this.name=e,this.number=t,this.funcs=s||[],this.func=n,this._value=null}get value(){return this._value}onFuncUpdate(e,t){return this.func!==this._makeFunc(e,t)&&(this._value=t,this.func=this._makeFunc(e,t),!0)}onValueRead(e){return this._value!==e&&this.type!==\"out\"&&(this._value=e,this.func=this._makeFunc(this.type,e),!0)}get type(){return this.func.startsWith(\"Out/\")?\"out\":this.func.startsWith(\"In/\")?\"in\":this.func}_makeFunc(e,t){return e==\"in\"?t===!0?\"In/High\":t===!1?\"In/Low\":\"In/Ind\":e==\"out\"?t===!0?\"Out/High\":t===!1?\"Out/Low\":\"Out/Ind\":e}}class Header{constructor(e,t){this.name=e,this.pins=t}}var Controller=new class{constructor(){this.eventGPIO=new EventSource,this.eventDone=new EventSource,this.eventLease=new EventSource,this.gpios={},this.headers={},this.leases={},this._inHeader={},this._polling={},this._pollingID=null,this._pollingRate=100,this._streamed={},this._leaseRate=5e3,document.addEventListener(\"DOMContentLoaded\",()=>{this._fetchGPIO(),this._fetchHeader(),this._stream(),this._fetchLeases()},{once:!0})}setGPIOIn(e){log(\"setGPIOIn(\"+e.name+\")\");let t={Name:e.name,Pull:\"\",Edge:window.EventSource?\"both\":\"\"};e.onFuncUpdate(\"in\",null),this.eventGPIO.dispatchEvent(e.name,\"in\"),postJSON(\"/api/periph/v1/gpio/in\",[t],e=>{if(!e[0])return;if(!t.Edge){alertError(e[0]);return}t.Edge=\"\",postJSON(\"/api/periph/v1/gpio/in\",[t],e=>{if(e[0]){alertError(e[0]);return}})})}setGPIOOut(e,t){log(\"setGPIOOut(\"+e.name+\", \"+t+\")\");let n={};n[e.name]=t,e.onFuncUpdate(\"out\",t),this.eventGPIO.dispatchEvent(e.name,\"out\"),postJSON(\"/api/periph/v1/gpio/out\",n,e=>{if(e[0]){alertError(e[0]);return}})}setGPIOPWM(e,t,n){log(\"setGPIOPWM(\"+e.name+\", \"+t+\", \"+n+\")\");let s={Name:e.name,Duty:t,Freq:n};e.onFuncUpdate(\"PWM\",null),this.eventGPIO.dispatchEvent(e.name,\"PWM\"),postJSON(\"/api/periph/v1/gpio/pwm\",[s],e=>{if(e[0]){alertError(e[0]);return}})}_fetchGPIO(){postJSON(\"/api/periph/v1/gpio/list\",{},e=>{log(\"/api/periph/v1/gpio/list\");for(let t=0;t<e.length;t++){let n=e[t].Name,s=new GPIO(n,e[t].Number,e[t].Func,e[t].Funcs);this.gpios[n]=s,this.eventGPIO.dispatchEvent(n)}this.eventDone.dispatchEvent(\"gpio\")})}_fetchHeader(){postJSON(\"/api/periph/v1/header/list\",{},e=>{log(\"/api/periph/v1/header/list\");for(let t in e){let n=[];for(let s=0;s<e[t].Pins.length;s++){let o=e[t].Pins[s],i=[];for(let t=0;t<o.length;t++){let n=o[t],e=new Pin(n.Name,n.Number,n.Func,this.gpios[n.Name]);e.gpio?(this._autoPoll(e.gpio),this.eventGPIO.addEventListener(e.gpio.name,()=>{this._autoPoll(e.gpio)})):this.eventGPIO.addEventListener(e.name,()=>{e.gpio=this.gpios[e.name],this._autoPoll(e.gpio),this.eventGPIO.addEventListener(e.gpio.name,()=>{this._autoPoll(e.gpio)})},{once:!0}),i[t]=e}n[s]=i}this.headers[t]=new Header(t,n)}this.eventDone.dispatchEvent(\"header\")})}_fetchLeases(){postJSON(\"/api/periph/v1/lease/list\",{},e=>{let t={};for(let n=0;n<e.length;n++){let s=e[n].Pins||[];for(let o=0;o<s.length;o++)t[s[o]]=e[n].Holder}let n=this.leases;this.leases=t;for(let e in n)n[e]!==t[e]&&this.eventLease.dispatchEvent(e,t[e]);for(let e in t)e in n||this.eventLease.dispatchEvent(e,t[e]);window.setTimeout(this._fetchLeases.bind(this),this._leaseRate)})}_autoPoll(e){this._inHeader[e.name]=e,e.type==\"in\"&&!this._streamed[e.name]?(log(\"Now polling \"+e.name),this._polling[e.name]=!0,this._pollingID===null&&(this._pollingID=window.setTimeout(this._refreshGPIO.bind(this),this._pollingRate))):(log(\"Stop polling \"+e.name),delete this._polling[e.name],this._pollingID&&!Object.keys(this._polling).length&&(window.clearTimeout(this._pollingID),this._pollingID=null))}_stream(){if(!window.EventSource)return;let e=new window.EventSource(\"/raw/periph/v1/gpio/events\");e.addEventListener(\"watch\",e=>{this._streamed={};let t=JSON.parse(e.data);for(let e=0;e<t.length;e++)this._streamed[t[e]]=!0;this._updateAutoPoll()}),e.addEventListener(\"edge\",e=>{let t=JSON.parse(e.data),n=this.gpios[t.Name];n&&n.onValueRead(t.Level)&&this.eventGPIO.dispatchEvent(n.name,t.Level)}),e.addEventListener(\"error\",e=>{log(\"Event stream disconnected\"),this._streamed={},this._updateAutoPoll()})}_updateAutoPoll(){for(let e in this._inHeader)this._autoPoll(this._inHeader[e])}_refreshGPIO(){let e=Object.keys(this._polling).sort();if(!e.length){this._pollingID=null;return}postJSON(\"/api/periph/v1/gpio/read\",e,t=>{for(let s=0;s<e.length;s++){let n=this.gpios[e[s]];switch(t[s]){case 0:n.onValueRead(!1)&&this.eventGPIO.dispatchEvent(n.name,!1);break;case 1:n.onValueRead(!0)&&this.eventGPIO.dispatchEvent(n.name,!0);break;default:n.onValueRead(null)&&this.eventGPIO.dispatchEvent(n.name,null);break}}this._pollingID=setTimeout(this._refreshGPIO.bind(this),this._pollingRate)})}};function fetchI2C(){postJSON(\"/api/periph/v1/i2c/list\",{},e=>{let n=document.getElementById(\"section-i2c\"),t=[];for(let s=0;s<e.length;s++){let o=n.appendChild(document.createElement(\"i2c-elem\"));o.setupI2C(e[s].Name,e[s].Number,e[s].Err,e[s].SCL,e[s].SDA),e[s].Err||t.push(e[s].Name)}t.length&&(n.appendChild(document.createElement(\"i2c-console-elem\")).setupBuses(t),n.appendChild(document.createElement(\"i2c-scan-elem\")).setupBuses(t))})}function parseBytes(e){let n=[],t=e.split(/[\\s,]+/);for(let e=0;e<t.length;e++){if(!t[e])continue;let s=Number(t[e]);if(!Number.isInteger(s)||s<0||s>255)throw new Error(\"invalid byte \"+t[e]);n.push(s)}return n}function formatBytes(e){return e.map(e=>\"0x\"+(\"0\"+e.toString(16).toUpperCase()).slice(-2)).join(\", \")}function fetchOneWire(){postJSON(\"/api/periph/v1/onewire/list\",{},e=>{let t=document.getElementById(\"section-onewire\");for(let n=0;n<e.length;n++)t.appendChild(document.createElement(\"onewire-elem\")).setupOneWire(e[n])})}function fetchSPI(){postJSON(\"/api/periph/v1/spi/list\",{},e=>{let n=document.getElementById(\"section-spi\"),t=[];for(let s=0;s<e.length;s++){let o=n.appendChild(document.createElement(\"spi-elem\"));o.setupSPI(e[s].Name,e[s].Number,e[s].Err,e[s].CLK,e[s].MOSI,e[s].MISO,e[s].CS),e[s].Err||t.push(e[s].Name)}t.length&&n.appendChild(document.createElement(\"spi-console-elem\")).setupPorts(t)})}function fetchState(){postJSON(\"/api/periph/v1/server/state\",{},e=>{document.title=\"periph-web - \"+e.Hostname;let t=document.getElementById(\"section-drivers-loaded\");if(e.State.Loaded.length){t.setupDrivers([\"Drivers loaded\"]);for(let n=0;n<e.State.Loaded.length;n++)t.appendRow([e.State.Loaded[n]])}else t.display=\"hidden\";if(t=document.getElementById(\"section-drivers-skipped\"),e.State.Skipped.length){t.setupDrivers([\"Drivers skipped\",\"Reason\"]);for(let n=0;n<e.State.Skipped.length;n++)t.appendRow([e.State.Skipped[n].D,e.State.Skipped[n].Err])}else t.display=\"hidden\";if(t=document.getElementById(\"section-drivers-failed\"),e.State.Failed.length){t.setupDrivers([\"Drivers failed\",\"Error\"]);for(let n=0;n<e.State.Failed.length;n++)t.appendRow([e.State.Failed[n].D,e.State.Failed[n].Err])}else t.display=\"hidden\"})}Controller.eventDone.addEventListener(\"header\",()=>{let e=document.getElementById(\"section-gpio\");Object.keys(Controller.headers).sort().forEach(t=>{e.appendChild(document.createElement(\"header-view\")).setupHeader(t)})},{once:!0}),document.addEventListener(\"DOMContentLoaded\",()=>{fetchI2C(),fetchOneWire(),fetchSPI(),fetchState()},{once:!0});class HTMLElementTemplate extends HTMLElement{constructor(e){super();let t=document.querySelector(\"template#\"+e);this.attachShadow({mode:\"open\"}).appendChild(t.content.cloneNode(!0))}static get observedAttributes(){return[]}emitEvent(e,t){this.dispatchEvent(new CustomEvent(e,{detail:t,bubbles:!0}))}}</script><template id=template-data-table-elem><style>th{background-color:#4caf50;color:#fff}th,td{padding:.5rem;border-bottom:1px solid #ddd}tr:hover{background-color:#ccc}tr:nth-child(even):not(:hover){background:#f5f5f5}.inline{display:inline-block;margin-bottom:1rem;margin-right:2rem;vertical-align:top}</style><div class=inline><table><thead></thead><tbody></tbody></table></div></template><script>\"use strict\";window.customElements.define(\"data-table-elem\",class extends HTMLElementTemplate{constructor(){super(\"template-data-table-elem\")}setupTable(e){let t=this.shadowRoot.querySelector(\"thead\");for(let n=0;n<e.length;n++)t.appendChild(document.createElement(\"th\")).innerText=e[n]}appendRow(e){let n=this.shadowRoot.querySelector(\"tbody\").appendChild(document.createElement(\"tr\")),t=[];for(let s=0;s<e.length;s++){let o=n.appendChild(document.createElement(\"td\"));e[s]instanceof Element?(o.appendChild(e[s]),t[s]=e[s]):(o.innerText=e[s],t[s]=o)}return t}})</script><template id=template-checkout-elem><style>@keyframes popIn{0%{transform:scale(1,1)}25%{transform:scale(1.2,1)}50%{transform:scale(1.4,1)}100%{transform:scale(1,1)}}@keyframes popOut{0%{transform:scale(1,1)}25%{transform:scale(1.2,1)}50%{transform:scale(1.4,1)}100%{transform:scale(1,1)}}div{display:inline-block;height:20px;position:relative;vertical-align:bottom}input{bottom:0;cursor:pointer;display:block;height:0%;left:0;margin:0;opacity:0;position:absolute;right:0;top:0;width:0%}span{cursor:pointer;margin-left:.25em;padding-left:40px;user-select:none}span:before{background:rgba(100,100,100,.2);border-radius:20px;box-shadow:inset 0 0 5px rgba(0,0,0,.8);content:\"\";display:inline-block;height:20px;left:0;position:absolute;transition:background .2s ease-out;width:40px}span:after{background-clip:padding-box;background:#fff;border-radius:20px;border:solid green 2px;content:\"\";display:block;font-weight:700;height:20px;left:-2px;position:absolute;text-align:center;top:-2px;transition:margin-left .1s ease-in-out;width:20px}input:checked+span:after{margin-left:20px}input:checked+span:before{transition:background .2s ease-in}input:not(:checked)+span:after{animation:popOut ease-in .3s normal}input:checked+span:after{animation:popIn ease-in .3s normal;background-clip:padding-box;margin-left:20px}input:checked+span:before{background:#20c997}input:disabled+span:before{box-shadow:0 0 black}input:disabled+span{color:#adb5bd}input:disabled:checked+span:before{background:#adb5bd}input:indeterminate+span:after{margin-left:10px}input:focus+span:before{outline:solid #cce5ff 2px}</style><div><label><input type=checkbox><span><slot></slot></span></label></div></template><script>\"use strict\";window.customElements.define(\"checkout-elem\",class extends HTMLElementTemplate{constructor(){super(\"template-checkout-elem\")}connectedCallback(){this.contentElem=this.shadowRoot.querySelector(\"span\"),this.checkboxElem=this.shadowRoot.querySelector(\"input\"),this.checkboxElem.addEventListener(\"click\",e=>{this.emitEvent(\"change\",{})},{passive:!0})}get checked(){return this.checkboxElem.checked}set checked(e){this.checkboxElem.checked=e}get disabled(){return this.checkboxElem.disabled}set disabled(e){this.checkboxElem.disabled=e}get indeterminate(){return this.checkboxElem.indeterminate}set indeterminate(e){this.checkboxElem.indeterminate=e}get text(){return this.contentElem.innerText}set text(e){this.contentElem.innerText=e}})</script><template id=template-drivers-elem><style>.inline{display:inline-block}</style><div class=inline><data-table-elem></data-table-elem></div></template><script>\"use strict\";window.customElements.define(\"drivers-elem\",class extends HTMLElementTemplate{constructor(){super(\"template-drivers-elem\")}setupDrivers(e){this.shadowRoot.querySelector(\"data-table-elem\").setupTable(e)}appendRow(e){this.shadowRoot.querySelector(\"data-table-elem\").appendRow(e)}})</script><template id=template-gpio-view><style>div{background:#ccc;border:1px solid #888;border-radius:10px;padding:10px}.gpio{background:#fcf}.controls{display:none}.box{border:1px solid #888;border-radius:6px;padding:3px}#func{display:none;background-color:#ccc;padding-bottom:3px;padding-right:3px;border-radius:3px}#lease{display:none;background-color:#fc8;padding:0 3px 3px;border-radius:3px}#pwm{display:none}#duty{vertical-align:middle;width:6em}#freq{width:4em}</style><div><span id=name>L</span>\n<span><span class=controls><span><checkout-elem id=io>I/O</checkout-elem>\n<checkout-elem id=level>Level</checkout-elem>\n</span></span><span id=func></span>\n<span id=pwm class=box>PWM\n<input id=duty type=range min=0 max=100 value=50 title=\"Duty cycle\">\n<span id=dutyText>50%</span>\n<input id=freq value=1kHz title=Frequency>\n</span><span id=lease></span></span></div></template><script>\"use strict\";window.customElements.define(\"gpio-view\",class extends HTMLElementTemplate{constructor(){super(\"template-gpio-view\")}connectedCallback(){this.funcElem=this.shadowRoot.getElementById(\"func\"),this.ioElem=this.shadowRoot.getElementById(\"io\"),this.levelElem=this.shadowRoot.getElementById(\"level\"),this.leaseElem=this.shadowRoot.getElementById(\"lease\"),this.pwmElem=this.shadowRoot.getElementById(\"pwm\"),this.dutyElem=this.shadowRoot.getElementById(\"duty\"),this.freqElem=this.shadowRoot.getElementById(\"freq\"),this.dutyElem.addEventListener(\"input\",e=>{this.shadowRoot.getElementById(\"dutyText\").textContent=this.dutyElem.value+\"%\"},{passive:!0}),this.dutyElem.addEventListener(\"change\",e=>this._setPWM(),{passive:!0}),this.freqElem.addEventListener(\"change\",e=>this._setPWM(),{passive:!0}),this.ioElem.addEventListener(\"change\",e=>{log(this.id+\".io.change(\"+this.ioElem.checked+\")\"),this.ioElem.checked?Controller.setGPIOOut(this.pin.gpio,this.levelElem.checked):Controller.setGPIOIn(this.pin.gpio)},{passive:!0}),this.levelElem.addEventListener(\"change\",e=>{log(this.id+\".level.change(\"+this.levelElem.checked+\")\"),this.ioElem.checked&&Controller.setGPIOOut(this.pin.gpio,this.levelElem.checked)},{passive:!0})}setupPin(e){if(this.pin=e,this.id=this.pin.name,this.shadowRoot.getElementById(\"name\").textContent=this.pin.name,this.pin.gpio){log(this.id+\" is GPIO\"),this._isGPIO();return}log(this.id+\" is indeterminate\"),this.pin.func&&(this.funcElem.textContent=this.pin.func,this.funcElem.style.display=\"inline-block\"),Controller.eventGPIO.addEventListener(this.pin.name,()=>{log(this.id+\" is GPIO (late)\"),this._isGPIO()},{once:!0})}_isGPIO(){Controller.eventGPIO.addEventListener(this.pin.name,()=>this._gpioUpdate()),Controller.eventLease.addEventListener(this.pin.name,()=>this._gpioUpdate()),this.shadowRoot.querySelector(\"div\").classList.add(\"gpio\"),this._gpioUpdate()}_setPWM(){Controller.setGPIOPWM(this.pin.gpio,this.dutyElem.value+\"%\",this.freqElem.value)}_gpioUpdate(){log(this.id+\"._gpioUpdate(\"+this.pin.func+\")\"),this.pin.func.startsWith(\"In/\")||this.pin.func.startsWith(\"Out/\")?(this.funcElem.textContent=\"\",this.funcElem.style.display=\"none\",this.shadowRoot.querySelector(\".controls\").style.display=\"inline-block\",this.ioElem.checked=this.pin.func.startsWith(\"Out/\"),this.ioElem.disabled=!1,this.ioElem.indeterminate=!1,this.levelElem.checked=this.pin.func.endsWith(\"/High\"),this.levelElem.disabled=!this.ioElem.checked,this.ioElem.checked?this.ioElem.text=\"Out\":this.ioElem.text=\"In\",this.levelElem.checked?(this.levelElem.text=\"High\",this.levelElem.indeterminate=!1):this.pin.func.endsWith(\"/Low\")?(this.levelElem.text=\"Low\",this.levelElem.indeterminate=!1):(this.levelElem.indeterminate=!0,this.levelElem.text=\"Ind\")):(this.pin.func?(this.funcElem.textContent=this.pin.func,this.funcElem.style.display=\"inline-block\"):(this.funcElem.textContent=\"\",this.funcElem.style.display=\"none\"),this.ioElem.checked=!1,this.ioElem.disabled=!this.pin.func.startsWith(\"PWM\"),this.ioElem.disabled||(this.shadowRoot.querySelector(\".controls\").style.display=\"inline-block\"),this.ioElem.indeterminate=!0,this.ioElem.text=\"I/O\",this.levelElem.checked=!1,this.levelElem.disabled=!0,this.levelElem.indeterminate=!0,this.levelElem.text=\"Level\"),this.pin.gpio.funcs.includes(\"PWM\")&&(this.pwmElem.style.display=\"inline-block\",this.dutyElem.disabled=!1,this.freqElem.disabled=!1);let e=Controller.leases[this.pin.name];e?(this.leaseElem.textContent=\"Leased by \"+e,this.leaseElem.style.display=\"inline-block\",this.ioElem.disabled=!0,this.levelElem.disabled=!0,this.dutyElem.disabled=!0,this.freqElem.disabled=!0):(this.leaseElem.textContent=\"\",this.leaseElem.style.display=\"none\")}})</script><template id=template-header-view><data-table-elem></data-table-elem></template><script>\"use strict\";window.customElements.define(\"header-view\",class extends HTMLElementTemplate{constructor(){super(\"template-header-view\")}setupHeader(e){this.header=Controller.headers[e];let t=this.shadowRoot.querySelector(\"data-table-elem\"),n=1;this.header.pins&&(n=this.header.pins[0].length);let s=[this.header.name];for(let e=1;e<n;e++)s[e]=\"\";t.setupTable(s);for(let n=0;n<this.header.pins.length;n++){let s=this.header.pins[n],e=[];for(let t=0;t<s.length;t++)e[t]=document.createElement(\"gpio-view\");e=t.appendRow(e);for(let t=0;t<e.length;t++)e[t].setupPin(s[t])}}})</script><template id=template-i2c-elem><data-table-elem></data-table-elem></template><script>\"use strict\";window.customElements.define(\"i2c-elem\",class extends HTMLElementTemplate{constructor(){super(\"template-i2c-elem\")}setupI2C(e,t,n,s,o){let i=this.shadowRoot.querySelector(\"data-table-elem\");i.setupTable([e,\"\"]),t!=-1&&i.appendRow([\"Number\",t]),n&&i.appendRow([\"Error\",n]),s&&i.appendRow([\"SCL\",s]),o&&i.appendRow([\"SDA\",o])}})</script><template id=template-i2c-console-elem><style>div{border:1px solid #888;border-radius:10px;display:inline-block;margin-bottom:1rem;padding:10px;vertical-align:top}input{width:4em}#w{width:16em}pre{font-family:monospace;max-height:20em;overflow-y:auto}</style><div><h3>Console</h3><form><label>Bus <select id=bus></select></label>\n<label>Addr <input id=addr placeholder=0x76 required></label>\n<label>Write <input id=w placeholder=0xD0></label>\n<label>Read <input id=r type=number min=0 max=4096 value=1></label>\n<button>Tx</button></form><pre id=log></pre></div></template><script>\"use strict\";window.customElements.define(\"i2c-console-elem\",class extends HTMLElementTemplate{constructor(){super(\"template-i2c-console-elem\")}connectedCallback(){this.shadowRoot.querySelector(\"form\").addEventListener(\"submit\",e=>{e.preventDefault(),this._tx()})}setupBuses(e){let t=this.shadowRoot.getElementById(\"bus\");for(let n=0;n<e.length;n++){let s=t.appendChild(document.createElement(\"option\"));s.value=e[n],s.innerText=e[n]}}_tx(){let e={Bus:this.shadowRoot.getElementById(\"bus\").value,Addr:Number(this.shadowRoot.getElementById(\"addr\").value),W:[],R:Number(this.shadowRoot.getElementById(\"r\").value)};try{e.W=parseBytes(this.shadowRoot.getElementById(\"w\").value)}catch(e){this._append(e.toString());return}let t=e.Bus+\" 0x\"+e.Addr.toString(16)+\" W[\"+formatBytes(e.W)+\"] R\"+e.R;postJSON(\"/api/periph/v1/i2c/tx\",[e],e=>{e[0].Err?this._append(t+\": \"+e[0].Err):this._append(t+\": [\"+formatBytes(e[0].R)+\"]\")})}_append(e){let t=this.shadowRoot.getElementById(\"log\");t.textContent=e+`\n`+t.textContent}})</script><template id=template-i2c-scan-elem><style>div{border:1px solid #888;border-radius:10px;display:inline-block;margin-bottom:1rem;padding:10px;vertical-align:top}table{border-collapse:collapse}th,td{font-family:monospace;padding:2px 4px;text-align:center}td.found{background:#8f8}td.busy{background:#fc6}</style><div><h3>Scan</h3><form><label>Bus <select id=bus></select></label>\n<label>Probe <select id=mode><option value=auto>auto</option><option value=quick>quick write</option><option value=read>read byte</option></select></label>\n<button>Scan</button></form><table id=grid></table><span id=status></span></div></template><script>\"use strict\";window.customElements.define(\"i2c-scan-elem\",class extends HTMLElementTemplate{constructor(){super(\"template-i2c-scan-elem\")}connectedCallback(){this.shadowRoot.querySelector(\"form\").addEventListener(\"submit\",e=>{e.preventDefault(),this._scan()})}setupBuses(e){let t=this.shadowRoot.getElementById(\"bus\");for(let n=0;n<e.length;n++){let s=t.appendChild(document.createElement(\"option\"));s.value=e[n],s.innerText=e[n]}}_scan(){let t={Bus:this.shadowRoot.getElementById(\"bus\").value,Mode:this.shadowRoot.getElementById(\"mode\").value},e=this.shadowRoot.getElementById(\"status\");e.textContent=\"Scanning...\",postJSON(\"/api/periph/v1/i2c/scan\",t,t=>{if(t.Err){e.textContent=t.Err;return}e.textContent=t.Found.length+\" device(s) found using \"+t.Mode+\" probing\",this._render(t.Found)})}_render(e){let o={};for(let t=0;t<e.length;t++)o[e[t].Addr]=e[t];let s=this.shadowRoot.getElementById(\"grid\");s.textContent=\"\";let n=e=>e.toString(16),t=s.appendChild(document.createElement(\"tr\"));t.appendChild(document.createElement(\"th\"));for(let e=0;e<16;e++)t.appendChild(document.createElement(\"th\")).textContent=n(e);for(let e=0;e<128;e+=16){t=s.appendChild(document.createElement(\"tr\")),t.appendChild(document.createElement(\"th\")).textContent=(\"0\"+n(e)).slice(-2);for(let r=0;r<16;r++){let s=e+r,a=t.appendChild(document.createElement(\"td\"));if(s<8||s>119)continue;let i=o[s];if(!i){a.textContent=\"--\";continue}a.textContent=i.Busy?\"UU\":(\"0\"+n(s)).slice(-2),a.className=i.Busy?\"busy\":\"found\",a.title=\"0x\"+n(s)+(i.Devices.length?\": \"+i.Devices.join(\", \"):\"\")}}}})</script><template id=template-onewire-elem><style>button{margin-bottom:1rem}</style><data-table-elem id=bus></data-table-elem><data-table-elem id=devices></data-table-elem><button id=read hidden>Read temperatures</button>\n</template><script>\"use strict\";window.customElements.define(\"onewire-elem\",class extends HTMLElementTemplate{constructor(){super(\"template-onewire-elem\"),this._name=\"\",this._temps={}}connectedCallback(){this.shadowRoot.getElementById(\"read\").addEventListener(\"click\",e=>{this._read()})}setupOneWire(e){this._name=e.Name;let t=this.shadowRoot.getElementById(\"bus\");t.setupTable([e.Name,\"\"]),e.Number!=-1&&t.appendRow([\"Number\",e.Number]),e.Err&&t.appendRow([\"Error\",e.Err]),e.Q&&t.appendRow([\"Q\",e.Q]);let n=this.shadowRoot.getElementById(\"devices\");n.setupTable([\"Address\",\"Family\",\"Temperature\"]);for(let s=0;s<e.Devices.length;s++){let t=e.Devices[s],o=\"0x\"+(\"0\"+t.Family.toString(16)).slice(-2);t.FamilyName&&(o+=\" \"+t.FamilyName);let i=n.appendRow([t.Addr,o,\"\"]);(t.FamilyName==\"DS18B20\"||t.FamilyName==\"DS18S20\")&&(this._temps[t.Addr]=i[2],this.shadowRoot.getElementById(\"read\").hidden=!1)}}_read(){postJSON(\"/api/periph/v1/onewire/ds18b20/read\",{Bus:this._name},e=>{for(let t=0;t<e.length;t++){let n=this._temps[e[t].Addr];n?n.innerText=e[t].Err||e[t].Text:e[t].Err&&this.shadowRoot.getElementById(\"bus\").appendRow([\"Error\",e[t].Err])}})}})</script><template id=template-history-elem><style>h1{font-size:24px;margin:.2em 0}select{margin-bottom:1rem}</style><h1>History</h1><label>Last <select id=range><option value=10m>10 minutes</option><option value=1h selected>hour</option><option value=6h>6 hours</option><option value=24h>24 hours</option></select></label><div id=charts></div></template><script>\"use strict\";window.customElements.define(\"history-elem\",class extends HTMLElementTemplate{constructor(){super(\"template-history-elem\"),this._charts={},this._timer=null}connectedCallback(){this.shadowRoot.getElementById(\"range\").addEventListener(\"change\",e=>{this._fetch()}),this._fetch()}disconnectedCallback(){clearTimeout(this._timer)}_fetch(){clearTimeout(this._timer);let e=this.shadowRoot.getElementById(\"range\").value;getJSON(\"/api/periph/v1/history?since=\"+e,t=>{if(t.Err)return;this.hidden=!1;let s=this.shadowRoot.getElementById(\"charts\"),n=Date.now(),o=n-parseDuration(e);for(let i=0;i<t.Series.length;i++){let e=t.Series[i];this._charts[e.Name]||(this._charts[e.Name]=s.appendChild(document.createElement(\"chart-elem\"))),this._charts[e.Name].draw(e,o,n)}this._timer=setTimeout(()=>this._fetch(),Math.max(t.Interval,5e3))})}});function parseDuration(e){let t={s:1e3,m:60*1e3,h:60*60*1e3};return Number(e.slice(0,-1))*t[e.slice(-1)]}</script><template id=template-chart-elem><style>div{border:1px solid #888;border-radius:10px;display:inline-block;margin:0 1rem 1rem 0;padding:10px;vertical-align:top}svg{display:block;height:150px;width:400px}polyline{fill:none;stroke:#4caf50;stroke-width:1.5;vector-effect:non-scaling-stroke}line{stroke:#ddd;vector-effect:non-scaling-stroke}.axis{color:#888;display:flex;font-size:12px;justify-content:space-between}</style><div><h3 id=title></h3><div class=axis><span id=max></span><span id=last></span></div><svg viewBox=\"0 0 1e3 100\" preserveAspectRatio=\"none\"><line x1=\"0\" y1=\"50\" x2=\"1e3\" y2=\"50\"/><polyline/></svg><div class=axis><span id=min></span><span id=range></span></div></div></template><script>\"use strict\";window.customElements.define(\"chart-elem\",class extends HTMLElementTemplate{constructor(){super(\"template-chart-elem\")}draw(e,t,n){let i=e.Unit?\" \"+e.Unit:\"\";this.shadowRoot.getElementById(\"title\").textContent=e.Name;let s=0,o=1;!e.Name.startsWith(\"gpio/\")&&e.V.length&&(s=Math.min(...e.V),o=Math.max(...e.V),s==o&&(s-=1,o+=1));let r=e=>((e-t)*1e3/(n-t)).toFixed(1),c=e=>(95-(e-s)*90/(o-s)).toFixed(1),a=[];for(let n=0;n<e.T.length;n++){if(e.T[n]<t)continue;e.Name.startsWith(\"gpio/\")&&n>0&&a.push(r(e.T[n])+\",\"+c(e.V[n-1])),a.push(r(e.T[n])+\",\"+c(e.V[n]))}this.shadowRoot.querySelector(\"polyline\").setAttribute(\"points\",a.join(\" \")),this.shadowRoot.getElementById(\"min\").textContent=formatNumber(s)+i,this.shadowRoot.getElementById(\"max\").textContent=formatNumber(o)+i,this.shadowRoot.getElementById(\"last\").textContent=e.V.length?\"now \"+formatNumber(e.V[e.V.length-1])+i:\"no data\",this.shadowRoot.getElementById(\"range\").textContent=new Date(t).toLocaleTimeString()+\" - \"+new Date(n).toLocaleTimeString()}});function formatNumber(e){return String(Math.round(e*1e3)/1e3)}</script><template id=template-spi-elem><data-table-elem></data-table-elem></template><script>\"use strict\";window.customElements.define(\"spi-elem\",class extends HTMLElementTemplate{constructor(){super(\"template-spi-elem\")}setupSPI(e,t,n,s,o,i,a){let r=this.shadowRoot.querySelector(\"data-table-elem\");r.setupTable([e,\"\"]),t!=-1&&r.appendRow([\"Number\",t]),n&&r.appendRow([\"Error\",n]),s&&r.appendRow([\"CLK\",s]),o&&r.appendRow([\"MOSI\",o]),o&&r.appendRow([\"MISO\",i]),a&&r.appendRow([\"CS\",a])}})</script><template id=template-spi-console-elem><style>div{border:1px solid #888;border-radius:10px;display:inline-block;margin-bottom:1rem;padding:10px;vertical-align:top}input{width:4em}#w{width:16em}pre{font-family:monospace;max-height:20em;overflow-y:auto}</style><div><h3>Console</h3><form><label>Port <select id=port></select></label>\n<label>Freq <input id=freq value=1MHz></label>\n<label>Mode <select id=mode><option>0</option><option>1</option><option>2</option><option>3</option></select></label>\n<label>Write <input id=w placeholder=\"0x9F 0 0 0\"></label>\n<button>Tx</button></form><pre id=log></pre></div></template><script>\"use strict\";window.customElements.define(\"spi-console-elem\",class extends HTMLElementTemplate{constructor(){super(\"template-spi-console-elem\")}connectedCallback(){this.shadowRoot.querySelector(\"form\").addEventListener(\"submit\",e=>{e.preventDefault(),this._tx()})}setupPorts(e){let t=this.shadowRoot.getElementById(\"port\");for(let n=0;n<e.length;n++){let s=t.appendChild(document.createElement(\"option\"));s.value=e[n],s.innerText=e[n]}}_tx(){let e={Port:this.shadowRoot.getElementById(\"port\").value,Freq:this.shadowRoot.getElementById(\"freq\").value,Mode:Number(this.shadowRoot.getElementById(\"mode\").value),W:[]};try{e.W=parseBytes(this.shadowRoot.getElementById(\"w\").value)}catch(e){this._append(e.toString());return}let t=e.Port+\" W[\"+formatBytes(e.W)+\"]\";postJSON(\"/api/periph/v1/spi/tx\",e,e=>{e.Err?this._append(t+\": \"+e.Err):this._append(t+\": [\"+formatBytes(e.R)+\"]\")})}_append(e){let t=this.shadowRoot.getElementById(\"log\");t.textContent=e+`\n`+t.textContent}})</script><div class=err id=err></div><h1>GPIO</h1><div id=section-gpio></div><history-elem id=section-history hidden></history-elem><div id=section-state><h1>periph's state</h1><div><drivers-elem id=section-drivers-loaded></drivers-elem><drivers-elem id=section-drivers-skipped></drivers-elem><drivers-elem id=section-drivers-failed></drivers-elem></div></div><h1>I²C</h1><div id=section-i2c></div><h1>1-Wire</h1><div id=section-onewire></div><h1>SPI</h1><div id=section-spi></div>"),
 	"static/favicon.ico": []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x80\x00\x00\x00\x80\b\x06\x00\x00\x00\xc3>a\xcb\x00\x00\ahIDATx\xda\xed\x9d\xcdkTW\x18\xc6\x7f9\x99aH\x98\xd1$^\xb5\"\xa6%Q\xf0\x83\ba6ҍ\xbb\xd6t\xd3\xddh5b\x15\xbb(T\xd4R\x84\xe4\x0fH@\nV\\\xeaF\x1c\xad\xceZ\xd0v\xe7\xce\xcdm\xc0\xc1\x0f*\t4RDs\xd5\xc4\f\xd1a\x92I\x17g\xc0\xceG\xe6+\xc9\xcc\xdcs\xde\xdfr`\xe6\xdey\x9e\xe7\xbe\xe7\xdcsν\xa7\x8d:\x89\xc7\xe3˅\x9f\r\x0f\x0f\xb7\xd1 \xca\x1dߋ\xc6:\xa1\xad\x17\xd4\x00\xb4\x0fB\xfb^\b\xf6Ap+\x04\"\x10\fA@\x81\xca}3\v,f!\x93\x86\xc5yȼ\x82\xcc\x14,=\x81\xa5\t\xc8&ay\xdaq\x13\v~\xf8\xff\xb5\x10\xc0 \xbc\xe8\xe9G\xb0a\x1f\x84Tm\xdfl\a\xda\x15\x84:\x80\x0e`\v0\x00|\x9b\xff\xfb?g\xe1\xfdc\xf8x\xf7\xbe!\x9a\x05\xfcitlWi\x036\x0f\xac\xef\x91C*w\x8c\x01$\x00\x8d6\xfd\xf0n\b]\x80\x9e\xe3\xd0\x11h\xcds<\x97\x81\xb77 }\xd1q\xef<\x93\x00\xac\xfeJ\x0fC\xf0\ft\x8fB$\xdc\xfarv\x04`\xfbI\xe0\xa4\x17\xfd)\x05\xef\xc6 s\xc5q\x13)\t@\x8d%\x1e:/ö!\xff\x16\xd7H\x18\"c\xc0\x98\x17\xfd\xfe\x1e,\x9cu\xdc\xc4s\t@Y\xe3\x8f\fB\xf8\xfa\xfa\xb7\xe5\x8df\xdb\x100\xe4EO'!u\xc2qoOH\x00\x8a\xda\xf7H\xc2<\xe3KuR7\xff\xa5\x830\x1fk\x85~B\xa0\xb9\xc6Ǻ\xa0\xf3\x96\xbfK}\xddAx\x9ak\x1a\x8e:nb\xb6Yg\xa2\x9ag\xfe\xb1\x11\xe8\x7fg\x9f\xf9\x85MC\xff;\xad\x85%\x15@\x97\xfb\x9e\aе\x05!G\xef\x98\x17\xfd\xf1\x1c\xbc=\xd8\xe8fA5\xd6\xfc\xe11\xe8{*既k\v\xf4=\xd5\x1a\x19V\x01\xbch́\x8d\x0f\xc1\xe9\x17\xa3+\xb1cċ\xfe\x10\x83\xb9\x03\x8e\x9b\xf0|_\x01\xbc\xe8w_Aߌ\x98_\vN?\xf4\xcdh\xed֗\xb6R\xb3J\x82=(\x91@\x02 H\x00\x04\t\x80`%u/a*\xd5y<t\xe9q\xc3N\xfc\xfe\xf9}\xd8{\xfc\xb97\xf7\xcf\x7f\xb9\xa9\xf0\xd3z\x96\x84I\x05\xf0%\x1b7I\x13 H\x00\x04\t\x80Ќ\x004b\x88Rh\xd1\x00艝\xcf\xff\x10\xe9Z\x0f\xedͺW\x80\x8d\x0fWq\xf7(\xac\xef\xdd\xc1\xc3u\r\x80\x9e\xab\x96Y\xbd\xd6\xc5\xe9\xafu=\x81\xaa\xde\xfcûaǈ\x88\xdc\xea\xec\x18\xd1^\xady\x05\xe8y \xe2\xfa\x85\xea\xbdR\xd5]\xfd\xc7Fd\x19\x97\x9f\xe8\xdaR\xedBSU\xd9\xfcX\x17\U0010e268~\xa3wL{\xb7\xea\n\xd0yK\xc4\xf4+\x95\xbdS\x95;~6\xaf\xdb\xf7;ۆ*u\b+T\x80HBD\xf4;\xe5=T+_\xfdG\x06\xcd\x7fV\xcf\x066\x0fh/k\xae\x00\xe1\xeb\"\x9e)\xac\xec\xa5Z\xa1\xe7\xbfK\xae~Ӫ@lW\r\x15\xa0\xf3\xb2\x88f\xdc\x1d\xc1\xe5\xaa\x02\xa0_\xcb\"=\x7f3\xef\bb\xe1**@\xf0\x8c\x88e*\xc5ޖ\b@\xf7\xa8\be*\xc5ު\xfc\xf2\x7fx\xb7?\xde\xc6%\xd49&\x10.\x1c\x18*\xa8\x00\xa1\v\"\x92\xe9\xe4{\\\x10\x80\x9e\xe3\"\x90\xe9\xe4{\xac\xf2\xef\xfd;\x02\"\x90\xe9t\x04\xfe?&P\xf7\xfb\x01\x86\x7f9\xd6r\x7f-\xfe\xeb͆\x1d˔\xff/\xcf\x05X\x8e\x04@\x02 H\x00\x04k\xc9m\xb1r\xfaQ\xad\xb3\x7fο\xc5\xcb\x04\xbd\xed\x8d\x1bD\x94\xe3\xaf\xf6\xf83Iǽ\xb6?W\x016\xec\x93k\xc16\xb4\xe7Jo\xb0\x14\x92\xa6\xc0:Bʋ\xc6:\x95\xde]K\xb0\xb4\aЫ\xf4\xd6j\x82\xa5\xf7\x00\x03J\xef\xab'\xd8I\xfb\xa0қ*\n\x96\x06`\xaf\xd2;j\nv\x12\xecSz;U\xc1\xd2\x00lUz/]\xc1N\x02\x11\xa57R\x16,\xad\x00!\xa5w\xd1\x16,\xad\x00J\xc9|\x90\xd5\xe3\x00\xe2\xbeD\x80\xac\xa8`-Y\x14,J\x02\xace1\xab \x93\x16!l%\x93V\xb08/BX[\x01\xe6\x15d^\x89\x10\xd6V\x80W\n2S\"\x84\xb5\x01\x98R\xb0\xf4D\x84\xb0\x95\xa5'\n\x96&D\bk\x030\xa1 \x9b\x14!\xac\x1d\aH*X\x9e\x16!leyZ9nb\x01\xd22\x18d\x1d\xe9\xac\xe3&\x16rs\x01\xef\x1f\x8b \xb6\xa1=\xcf\x05\xe0\xe3]\x11\xc46\xb4\xe7m|\xb6\xbc\xbcV?)\xcf\xe7\xfb\xef\xff\xcbt\xb0\xe5H\x00$\x00\x82\x04@\xb0\x96\xbc\x1d \xbd\xe8\xb9L\xb5o\n\xbb\x7f\xbe\xf8\x89\xf2C\x97\x1aw7)ǯ\xf7\xf8\x1f\x16\x1d\xf7\xb7\xe0\n\x15\xe0\xed\r\xb9&L'\xdf\xe3\x82\x00\xa4/\x8a@\xa6\x93\xefq^\x00\x1c\xf7\xce3\x98O\x89H\xa62\x9f\xd2\x1e\x97\xed\x04\xbe\x93=\x02\x8d\xa5\xd8\xdb\x12\x01\xc8\\\x11\xa1L\xa5\xd8ۢ\x008n\"\x05/\xef\x89X\xa6\xf1\xf2\x9e\xf6\xb6\xaaq\x80\x85\xb3\"\x98i\x94\xf6\xb4d\x00\x1c7\xf1\x1cfd\xa5\x901\xcc$\xb5\xa7U\x06@\x93:!\u0099\xc2\xca^\xae\x18\x00ǽ=!U\xc0\x94\xab\xff\xf6D\xcd\x01\xc8\xdd7\xc6D@\xdf\xdf\xfb\x97\xf5\xb0l\x00\xf4\xa0\x81\xdc\x11\xf8\xbb\xe7\x9f?\xf0Sc\x05\x00X8*B\xfa\xb6\xe7_ѻ\x8a\x01p\xdc\xc4,L\xcb^\x82\xbeczT{\xb7\xca\x00\xe8\x10\xdc\x1c\x87\xd9\xd7\"\xaa_\x98}\xad=\xabL\r\vB\xde\x1e\x14a\xfdB\xf5^U\x1d\x00ݙx1.\xe2\xb6:/\xc6+u\xfc\xea\xac\x00\xe0\xb8\xf1Q\xf0&E\xe4Vś\xd4\x1eUO\x1dk\x02\xe7\x0e\xc0\xb2hݒ\xcc\x1d\xa8\xf5\x1b5\a\xc0q\x13\x1e\xfc\xf3\xb5\x88\xddzho\xd69\x00\xfa@\xbf\xff)r\x9b\x81,\v\x97\x00\b\x12\x00A\x02 \xf8\xaa\xb7\xff\xa6E\x03\xb0v'&\x94\xd3\xd8\xfbb\xad~\xad-\x1e\x8f\xcbM\xbd4\x01\x82\x04@\x90\x00\b\x12\x00\xc12\xda\xea\xfdb\xa9\xce\xe3\xf0\xf0p\xc9\xdf\xf3\xa21\a6>\x04\xa7\x7f\xadN\xdc\xec\xf7\x03x\x930w\xa0\xdc\xd8~-\xfa7\xbd\x028n\xc2sܫ;e=A5\xbc\x18wܫ;\xeb\x99\xd8i\xf9&@\xcfUO\xed\x91\xe5e\xa5\x98}\rS{j\x9d\xcf_-\x81F\xff\xcd\xdcj\x95\xad^\xf4\xd8\b\xf4ʣ\xe8\x80^\xc0y\xb3)ձi\x9d@\xfd\x87'\xbb\xed~\xee\xe0\xe5=\x98\xecn\x96\xf9M\xa9\x00\x05}\x83Y\xe0\x1b/zx7D\x12\xb0y\xc0\x0e\xe3g\x920\x1f\xabe힑\x01(h\x16\xf6{\xd1#\x83\x10\xbenn\x10f\x92\x90:Q\xeeY=+\x03\xf0)\b\xb7't\x10b\xbb\xa0\xf32l\x1b2\xa7\xd4/\x9c]\xe9\x11m\t@q\xd3\xf0\\7\r\xb10\x04\xcf@\xf7(D\xc2\xfe2}>\xa5\xdfɓ\xb9R\xea\xcd\x1c\x12\x80ꂐ\x02Ɓq\xddO\b]\x80\x9e\xe3վ̲\xf1|X\xd4\xef\xe1K_l\x85\xf6\xdd\xf7\x01(\xd1O8\x05\x9c\xd2Mľ\xbf[\xef\x1c?\xbd\x81\xd3/\xf8r.`\xe5\xb6t&\xb9\xbe\xdbߤ\xb3\xfa\x18\xe6\x8ch\x060\bǽ\xb6\x1f\xc0\x8b\xc6:\xa1\xad\x17\xd4\x00\xb4\x0fB\xfb^\b\xf6Ap+\x04\"\x10\fA@}\xca\x7f\x16\xbd\x89v&\xad\xb7\xd2ͼ\xd2\x1bj.=\xd1\xdb\xeae\x93\xb0<\xad\xf7W\xd2\xc494b\x82f\xff\x01\xf7Qi\xbd}\xf6\x1b\xc6\x00\x00\x00\x00IEND\xaeB`\x82"),
 }
@@ -0,0 +1,150 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// selfSignedValidity is the validity of a generated self-signed certificate.
+const selfSignedValidity = 5 * 365 * 24 * time.Hour
+
+// loadTLS returns the TLS configuration to serve with the certificate and key
+// at certFile and keyFile.
+//
+// When selfSigned is true and the files do not exist, a self-signed
+// certificate is generated for host and persisted there, so the same
+// certificate is used on the next run. When the file paths are empty, they
+// default to the user's configuration directory.
+func loadTLS(certFile, keyFile string, selfSigned bool, host string) (*tls.Config, error) {
+	if selfSigned && (certFile == "" || keyFile == "") {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(dir, "periph-web")
+		if certFile == "" {
+			certFile = filepath.Join(dir, "cert.pem")
+		}
+		if keyFile == "" {
+			keyFile = filepath.Join(dir, "key.pem")
+		}
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("both -tls-cert and -tls-key must be specified")
+	}
+	if selfSigned {
+		if _, err := os.Stat(certFile); os.IsNotExist(err) {
+			if err = genSelfSigned(certFile, keyFile, host); err != nil {
+				return nil, fmt.Errorf("failed to generate self-signed certificate: %v", err)
+			}
+			fmt.Printf("Generated self-signed certificate %s\n", certFile)
+		}
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// genSelfSigned generates a self-signed certificate valid for host, the
+// hostname, localhost and all the local IP addresses.
+func genSelfSigned(certFile, keyFile, host string) error {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	tmpl := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"periph-web"}, CommonName: hostname},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	tmpl.DNSNames, tmpl.IPAddresses = certNames(host, hostname, addrs)
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		return err
+	}
+	key, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(certFile), 0700); err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(keyFile), 0700); err != nil {
+		return err
+	}
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0600); err != nil {
+		return err
+	}
+	return os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+}
+
+// certNames returns the names and the IP addresses a certificate for host
+// must be valid for.
+//
+// When host is the unspecified address, e.g. "0.0.0.0" or "::", the server is
+// reached through any of the interfaces' addresses, which are always included,
+// but never through the unspecified address itself.
+func certNames(host, hostname string, addrs []net.Addr) ([]string, []net.IP) {
+	names := []string{hostname, "localhost"}
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		if !ip.IsUnspecified() {
+			ips = append(ips, ip)
+		}
+	} else if host != "" && host != hostname && host != "localhost" {
+		names = append(names, host)
+	}
+	for _, a := range addrs {
+		if n, ok := a.(*net.IPNet); ok && !n.IP.IsUnspecified() && !containsIP(ips, n.IP) {
+			ips = append(ips, n.IP)
+		}
+	}
+	return names, ips
+}
+
+func containsIP(l []net.IP, ip net.IP) bool {
+	for _, i := range l {
+		if i.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
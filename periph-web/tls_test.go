@@ -0,0 +1,76 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/x509"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCertNames(t *testing.T) {
+	addrs := []net.Addr{
+		&net.IPNet{IP: net.ParseIP("127.0.0.1"), Mask: net.CIDRMask(8, 32)},
+		&net.IPNet{IP: net.ParseIP("192.0.2.1"), Mask: net.CIDRMask(24, 32)},
+		&net.IPNet{IP: net.ParseIP("::1"), Mask: net.CIDRMask(128, 128)},
+	}
+	local := []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("192.0.2.1"), net.ParseIP("::1")}
+	for _, c := range []struct {
+		host  string
+		names []string
+		ips   []net.IP
+	}{
+		{"0.0.0.0", []string{"pi", "localhost"}, local},
+		{"::", []string{"pi", "localhost"}, local},
+		{"localhost", []string{"pi", "localhost"}, local},
+		{"pi", []string{"pi", "localhost"}, local},
+		{"pi.example.com", []string{"pi", "localhost", "pi.example.com"}, local},
+		// The host address comes first.
+		{"192.0.2.1", []string{"pi", "localhost"}, []net.IP{local[1], local[0], local[2]}},
+		{"198.51.100.1", []string{"pi", "localhost"}, append([]net.IP{net.ParseIP("198.51.100.1")}, local...)},
+	} {
+		names, ips := certNames(c.host, "pi", addrs)
+		if !reflect.DeepEqual(names, c.names) || !reflect.DeepEqual(ips, c.ips) {
+			t.Errorf("%s: %v %v", c.host, names, ips)
+		}
+	}
+}
+
+func TestLoadTLSSelfSigned(t *testing.T) {
+	dir := t.TempDir()
+	cert := filepath.Join(dir, "cert.pem")
+	key := filepath.Join(dir, "key.pem")
+	cfg, err := loadTLS(cert, key, true, "0.0.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := x509.ParseCertificate(cfg.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostname, _ := os.Hostname()
+	if !contains(c.DNSNames, hostname) || !contains(c.DNSNames, "localhost") {
+		t.Fatal(c.DNSNames)
+	}
+	for _, ip := range c.IPAddresses {
+		if ip.IsUnspecified() {
+			t.Fatal(c.IPAddresses)
+		}
+	}
+	// The certificate is reused on the next run.
+	cfg2, err := loadTLS(cert, key, true, "0.0.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.Certificates[0].Certificate, cfg2.Certificates[0].Certificate) {
+		t.Fatal("the certificate was regenerated")
+	}
+	if _, err = loadTLS(cert, "", false, ""); err == nil {
+		t.Fatal("expected error")
+	}
+}
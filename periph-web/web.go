@@ -9,8 +9,10 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"crypto/subtle"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -28,6 +30,10 @@ import (
 
 const cacheControlNone = "Cache-Control:no-cache,private"
 
+// maxRequestSize is the maximum size of the body of a JSON API request, so a
+// client can't make the server buffer an unbounded amount of data.
+const maxRequestSize = 4 << 20
+
 type apiHandler struct {
 	path string
 	fn   interface{}
@@ -36,8 +42,17 @@ type apiHandler struct {
 type webServer struct {
 	ln     net.Listener
 	server http.Server
-	apis   jsonAPI
-	key    [8]byte
+	// mux routes the requests. Each server has its own so several can run in
+	// the same process, e.g. in tests.
+	mux  *http.ServeMux
+	apis jsonAPI
+	key  [8]byte
+	// metrics are the HTTP requests served, exposed at /metrics.
+	metrics httpMetrics
+	// openAPI is the OpenAPI document describing the JSON API.
+	openAPI []byte
+	// secure is true when serving over TLS.
+	secure bool
 }
 
 func getHostAndPort(hostport string) (string, int, error) {
@@ -65,49 +80,121 @@ func isLocalhost(host string) bool {
 	return false
 }
 
-func newWebServer(hostport string, state *driverreg.State, verbose bool) (*webServer, error) {
+// webOpts are the optional settings of the web server.
+type webOpts struct {
+	// verbose enables logging of every HTTP request.
+	verbose bool
+	// auth, when set, requires every request to be authenticated.
+	auth *authenticator
+	// tls, when set, serves over HTTPS instead of HTTP.
+	tls *tls.Config
+	// devices are exposed via /api/periph/v1/devices/.
+	devices []*device
+	// unixMode is the permissions of the Unix domain socket, when listening on
+	// one.
+	unixMode os.FileMode
+	// audit, when set, records the calls changing the GPIOs.
+	audit *auditLog
+	// history, when set, enables sampling the GPIOs and the sensors for
+	// /api/periph/v1/history.
+	history *historyOpts
+	// cors are the origins allowed to call the APIs from a browser, or "*"
+	// for any. "*" requires auth.
+	cors []string
+}
+
+func newWebServer(hostport string, state *driverreg.State, opts *webOpts) (*webServer, error) {
+	for _, o := range opts.cors {
+		// Any web page visited by a user on the network could fetch a XSRF
+		// token and drive the GPIOs.
+		if o == "*" && opts.auth == nil {
+			return nil, errors.New("-cors * requires -auth; list the allowed origins instead")
+		}
+	}
+	mux := http.NewServeMux()
 	s := &webServer{
 		server: http.Server{
-			Handler:           http.DefaultServeMux,
+			Handler:           mux,
 			ReadHeaderTimeout: 10 * time.Second,
 		},
+		mux: mux,
 	}
 	if _, err := rand.Read(s.key[:]); err != nil {
 		return nil, err
 	}
 	var err error
-	host, port, err := getHostAndPort(hostport)
-	if err != nil {
+	if s.ln, err = listen(hostport, opts.unixMode); err != nil {
 		return nil, err
 	}
+	if opts.auth != nil {
+		s.server.Handler = opts.auth.requireAuth(s.server.Handler)
+	}
+	if len(opts.cors) != 0 {
+		s.server.Handler = corsHandler(opts.cors, s.server.Handler)
+	}
 	hostname := ""
-	if isLocalhost(host) {
+	switch {
+	case isUnix(s.ln):
+		// Access is controlled by the permissions of the socket. The peer
+		// credentials identify the client.
+		hostname = "localhost"
+		s.server.ConnContext = peerContext
+		s.server.Addr = unixPrefix + s.ln.Addr().String()
+	case isLoopback(s.ln):
 		hostname = "localhost"
 		s.server.Handler = localOnly(s.server.Handler)
-	} else {
+		s.server.Addr = s.ln.Addr().String()
+	default:
 		if hostname, err = os.Hostname(); err != nil {
+			_ = s.ln.Close()
 			return nil, err
 		}
+		s.server.Addr = s.ln.Addr().String()
 	}
 
 	// Setup handlers.
 	s.apis.init(hostname, state)
-	for _, h := range s.apis.getAPIs() {
-		http.HandleFunc(h.path, s.api(h.fn))
+	s.apis.devices = opts.devices
+	s.apis.audit = opts.audit
+	if opts.history != nil {
+		if s.apis.history, err = newHistory(&s.apis, opts.history); err != nil {
+			_ = s.ln.Close()
+			return nil, err
+		}
+	}
+	apis := s.apis.getAPIs()
+	for _, h := range apis {
+		s.mux.HandleFunc(h.path, s.api(h.fn))
+	}
+	if s.openAPI, err = genOpenAPI(hostname, apis, opts.auth); err != nil {
+		if s.apis.history != nil {
+			// Stop the sampling goroutine.
+			_ = s.apis.history.close()
+		}
+		_ = s.ln.Close()
+		return nil, err
 	}
 	s.addOtherHandlers()
-	if verbose {
+	// Event streams never terminate by themselves so they must be closed for
+	// Shutdown() to complete.
+	s.server.RegisterOnShutdown(s.apis.events.close)
+	s.server.Handler = metricsHandler(&s.metrics, s.mux, s.server.Handler)
+	if opts.verbose {
 		s.server.Handler = loggingHandler(s.server.Handler)
 	}
 
 	// Start serving.
-	if s.ln, err = net.Listen("tcp", fmt.Sprintf("%s:%d", host, port)); err != nil {
-		return nil, err
+	if opts.tls != nil {
+		s.secure = true
+		s.server.TLSConfig = opts.tls
+		go func() {
+			_ = s.server.ServeTLS(s.ln, "", "")
+		}()
+	} else {
+		go func() {
+			_ = s.server.Serve(s.ln)
+		}()
 	}
-	s.server.Addr = s.ln.Addr().String()
-	go func() {
-		_ = s.server.Serve(s.ln)
-	}()
 	return s, nil
 }
 
@@ -137,13 +224,21 @@ func (s *webServer) validateToken(token string, userID string) bool {
 	return subtle.ConstantTimeCompare([]byte(token), []byte(s.generateToken(userID, issueTime))) == 1
 }
 
-func (s *webServer) setXSRFCookie(addr string, w http.ResponseWriter) string {
-	t := s.generateToken(strings.SplitN(addr, ":", 2)[0], time.Now())
+// setXSRFCookie sets a fresh XSRF token bound to the user identified by id,
+// as returned by userID().
+func (s *webServer) setXSRFCookie(id string, w http.ResponseWriter) string {
+	t := s.generateToken(id, time.Now())
 	c := http.Cookie{
 		Name:   "XSRF-TOKEN",
 		Value:  t,
 		MaxAge: 23 * 60 * 60,
 	}
+	if s.secure {
+		// The web UI doesn't need to read the cookie, it is only sent back.
+		c.Secure = true
+		c.HttpOnly = true
+		c.SameSite = http.SameSiteStrictMode
+	}
 	http.SetCookie(w, &c)
 	return t
 }
@@ -166,19 +261,28 @@ func localOnly(h http.Handler) http.Handler {
 
 // enforceXSRF is an handler wrapper that enforces the XSRF token.
 //
+// The token is read from the xsrfHeader header if present, otherwise from the
+// XSRF-TOKEN cookie.
+//
 // In practice it's only used for the APIs within the api() decorator.
 func (s *webServer) enforceXSRF(h http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		c, _ := r.Cookie("XSRF-TOKEN")
-		if c == nil {
-			log.Printf("Missing XSRF-TOKEN cookie")
-			http.Error(w, "Missing XSRF-TOKEN cookie", 400)
-			_ = r.Body.Close()
-			return
+		src := xsrfHeader + " header"
+		t := r.Header.Get(xsrfHeader)
+		if t == "" {
+			src = "XSRF-TOKEN cookie"
+			c, _ := r.Cookie("XSRF-TOKEN")
+			if c == nil {
+				log.Printf("Missing XSRF-TOKEN cookie")
+				http.Error(w, "Missing XSRF-TOKEN cookie or "+xsrfHeader+" header", 400)
+				_ = r.Body.Close()
+				return
+			}
+			t = c.Value
 		}
-		if !s.validateToken(c.Value, strings.SplitN(r.RemoteAddr, ":", 2)[0]) {
-			log.Printf("Invalid XSRF-TOKEN cookie %q", c.Value)
-			http.Error(w, "Invalid XSRF-TOKEN cookie", 400)
+		if !s.validateToken(t, userID(r)) {
+			log.Printf("Invalid %s %q", src, t)
+			http.Error(w, "Invalid "+src, 400)
 			_ = r.Body.Close()
 			return
 		}
@@ -223,9 +327,15 @@ func (s *webServer) api(h interface{}) http.HandlerFunc {
 	if t.Kind() != reflect.Func {
 		panic("send API func")
 	}
+	// The first argument is optionally the caller.
+	withCaller := t.NumIn() != 0 && t.In(0) == callerType
+	nArg := t.NumIn()
+	if withCaller {
+		nArg--
+	}
 	var inT reflect.Type
-	if nArg := t.NumIn(); nArg == 1 {
-		inT = t.In(0)
+	if nArg == 1 {
+		inT = t.In(t.NumIn() - 1)
 	} else if nArg != 0 {
 		panic("pass func that accepts zero or one arg")
 	}
@@ -249,23 +359,43 @@ func (s *webServer) api(h interface{}) http.HandlerFunc {
 			http.Error(w, "Content-Type must be application/json", 400)
 			return
 		}
-		d := json.NewDecoder(r.Body)
+		d := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
 		// d.DisallowUnknownFields() is only available in Go 1.10+.
 		callDisallowUnknownFields(d)
 		var in []reflect.Value
+		if withCaller {
+			c := &caller{lease: r.Header.Get(leaseHeader), user: requestUser(r), remote: r.RemoteAddr, endpoint: r.URL.Path}
+			if c.user == "" {
+				c.user = userID(r)
+			}
+			in = append(in, reflect.ValueOf(c))
+		}
 		if inT != nil {
 			inv := reflect.New(inT)
 			if err := d.Decode(inv.Interface()); err != nil {
+				if errors.As(err, new(*http.MaxBytesError)) {
+					http.Error(w, "Request too large", http.StatusRequestEntityTooLarge)
+					return
+				}
 				http.Error(w, fmt.Sprintf("Malformed user data: %v", err), 400)
+				return
+			}
+			// A null body decodes as a nil pointer, which the handlers don't
+			// expect.
+			if inT.Kind() == reflect.Ptr && inv.Elem().IsNil() {
+				http.Error(w, "Malformed user data: null", 400)
+				return
 			}
 			in = append(in, inv.Elem())
 		} else {
 			var m map[string]string
 			if err := d.Decode(&m); err != nil {
 				http.Error(w, fmt.Sprintf("Malformed user data: %v", err), 400)
+				return
 			}
 			if len(m) != 0 {
 				http.Error(w, "Unexpected data", 400)
+				return
 			}
 		}
 		out := v.Call(in)
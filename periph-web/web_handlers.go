@@ -10,11 +10,15 @@ package main
 import "net/http"
 
 func (s *webServer) addOtherHandlers() {
-	http.HandleFunc("/raw/periph/v1/xsrf_token", noContent(s.apiXSRFTokenHandler))
-	http.HandleFunc("/favicon.ico", getOnly(s.getFavicon))
+	s.mux.HandleFunc("/raw/periph/v1/xsrf_token", noContent(s.apiXSRFTokenHandler))
+	s.mux.HandleFunc("/raw/periph/v1/gpio/events", s.enforceXSRF(getOnly(s.getGPIOEvents)))
+	s.mux.HandleFunc("/favicon.ico", getOnly(s.getFavicon))
+	s.mux.HandleFunc("/metrics", getOnly(s.getMetrics))
+	s.mux.HandleFunc("/api/periph/v1/openapi.json", getOnly(s.getOpenAPI))
+	s.mux.HandleFunc("/api/periph/v1/history", s.enforceXSRF(getOnly(s.getHistory)))
 	// Do not use getOnly here as it is the 'catch all, one and we want to check
 	// that before the method.
-	http.HandleFunc("/", noContent(s.getRoot))
+	s.mux.HandleFunc("/", noContent(s.getRoot))
 }
 
 // Static handlers
@@ -29,7 +33,7 @@ func (s *webServer) getRoot(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	s.setXSRFCookie(r.RemoteAddr, w)
+	s.setXSRFCookie(userID(r), w)
 	content := getContent("static/index.html")
 	if content == nil {
 		http.Error(w, "Content missing", 500)
@@ -60,7 +64,7 @@ func (s *webServer) apiXSRFTokenHandler(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Only POST is allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	t := s.setXSRFCookie(r.RemoteAddr, w)
+	t := s.setXSRFCookie(userID(r), w)
 	w.Header().Set("Content-Type", "text/plain")
 	w.Header().Set("Cache-Control", cacheControlNone)
 	w.WriteHeader(200)
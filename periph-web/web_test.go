@@ -0,0 +1,685 @@
+// Copyright 2026 The Periph Authors. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"periph.io/x/conn/v3/conntest"
+	"periph.io/x/conn/v3/driver/driverreg"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/conn/v3/gpio/gpiotest"
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/i2c/i2creg"
+	"periph.io/x/conn/v3/i2c/i2ctest"
+	"periph.io/x/conn/v3/onewire"
+	"periph.io/x/conn/v3/onewire/onewirereg"
+	"periph.io/x/conn/v3/onewire/onewiretest"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+	"periph.io/x/conn/v3/spi/spireg"
+	"periph.io/x/conn/v3/spi/spitest"
+)
+
+// TestWebAPI calls every JSON API against fake hardware.
+func TestWebAPI(t *testing.T) {
+	f := registerFakes(t)
+	ts := newTestServer(t, &webOpts{})
+	tests := map[string]func(t *testing.T){
+		"/api/periph/v1/devices/list": func(t *testing.T) {
+			var out []deviceRef
+			ts.post(t, "/api/periph/v1/devices/list", map[string]string{}, &out, 200)
+			if len(out) != 0 {
+				t.Fatal(out)
+			}
+		},
+		"/api/periph/v1/gpio/aliases": func(t *testing.T) {
+			var out []pinAlias
+			ts.post(t, "/api/periph/v1/gpio/aliases", map[string]string{}, &out, 200)
+			if !contains(out, pinAlias{"WEB_ALIAS", "WEB1"}) {
+				t.Fatal(out)
+			}
+		},
+		"/api/periph/v1/gpio/in": func(t *testing.T) {
+			var out []string
+			ts.post(t, "/api/periph/v1/gpio/in", []pinIn{{Name: "WEB1", Pull: "up"}, {Name: "NOPE"}}, &out, 200)
+			if !reflect.DeepEqual(out, []string{"", "Pin not found"}) {
+				t.Fatal(out)
+			}
+			if f.pins[0].P != gpio.PullUp || f.pins[0].L != gpio.High {
+				t.Fatal(f.pins[0])
+			}
+		},
+		"/api/periph/v1/gpio/list": func(t *testing.T) {
+			var out []gpioPin
+			ts.post(t, "/api/periph/v1/gpio/list", map[string]string{}, &out, 200)
+			if !contains(out, gpioPin{Name: "WEB1", Num: 2001, Func: "In/Low", Funcs: []string{"IN", "OUT"}}) {
+				t.Fatal(out)
+			}
+		},
+		"/api/periph/v1/gpio/read": func(t *testing.T) {
+			_ = f.pins[1].Out(gpio.High)
+			var out []int
+			ts.post(t, "/api/periph/v1/gpio/read", []string{"WEB2", "NOPE"}, &out, 200)
+			if !reflect.DeepEqual(out, []int{1, -1}) {
+				t.Fatal(out)
+			}
+		},
+		"/api/periph/v1/gpio/out": func(t *testing.T) {
+			var out []string
+			ts.post(t, "/api/periph/v1/gpio/out", map[string]bool{"WEB2": false}, &out, 200)
+			if !reflect.DeepEqual(out, []string{""}) || f.pins[1].L != gpio.Low {
+				t.Fatal(out)
+			}
+		},
+		"/api/periph/v1/gpio/pwm": func(t *testing.T) {
+			var out []string
+			ts.post(t, "/api/periph/v1/gpio/pwm", []pinPWM{{"WEB1", "25%", "1kHz"}, {"WEB2", "25%", "0Hz"}}, &out, 200)
+			if !reflect.DeepEqual(out, []string{"", "frequency must be positive"}) {
+				t.Fatal(out)
+			}
+			if f.pins[0].D != gpio.DutyMax/4 || f.pins[0].F != physic.KiloHertz {
+				t.Fatal(f.pins[0])
+			}
+		},
+		"/api/periph/v1/header/list": func(t *testing.T) {
+			var out map[string]header
+			ts.post(t, "/api/periph/v1/header/list", map[string]string{}, &out, 200)
+		},
+		"/api/periph/v1/i2c/list": func(t *testing.T) {
+			var out []i2cRef
+			ts.post(t, "/api/periph/v1/i2c/list", map[string]string{}, &out, 200)
+			if !contains(out, i2cRef{Name: "WEBI2C", Aliases: []string{}, Number: 99, SCL: "INVALID", SDA: "INVALID"}) {
+				t.Fatal(out)
+			}
+		},
+		"/api/periph/v1/i2c/scan": func(t *testing.T) {
+			ts.post(t, "/api/periph/v1/i2c/scan", nil, nil, 400)
+			f.setI2C(i2ctest.IO{Addr: 0x76, R: []byte{0}})
+			var out i2cScanOut
+			ts.post(t, "/api/periph/v1/i2c/scan", &i2cScanIn{Bus: "WEBI2C", Mode: "read"}, &out, 200)
+			if out.Err != "" || len(out.Found) != 1 || out.Found[0].Addr != 0x76 {
+				t.Fatal(out)
+			}
+		},
+		"/api/periph/v1/i2c/tx": func(t *testing.T) {
+			f.setI2C(i2ctest.IO{Addr: 0x76, W: []byte{0xD0}, R: []byte{0x60}})
+			var out []i2cTxResult
+			ts.post(t, "/api/periph/v1/i2c/tx", []i2cTx{{Bus: "WEBI2C", Addr: 0x76, W: byteList{0xD0}, R: 1}, {Bus: "WEBI2C", Addr: 1 << 10}}, &out, 200)
+			if len(out) != 2 || !bytes.Equal(out[0].R, []byte{0x60}) || out[0].Err != "" || out[1].Err != "invalid address" {
+				t.Fatal(out)
+			}
+			ts.post(t, "/api/periph/v1/i2c/tx", []i2cTx{{Bus: "NOPE"}}, &out, 400)
+		},
+		"/api/periph/v1/lease/acquire": func(t *testing.T) {
+			ts.post(t, "/api/periph/v1/lease/acquire", nil, nil, 400)
+			var l leaseOut
+			ts.post(t, "/api/periph/v1/lease/acquire", &leaseIn{Holder: "test", Pins: []string{"WEB2"}, I2C: []string{"WEBI2C"}}, &l, 200)
+			if l.ID == "" || l.Err != "" {
+				t.Fatal(l)
+			}
+			defer ts.post(t, "/api/periph/v1/lease/release", &leaseRef{ID: l.ID}, nil, 200)
+			// The resources can't be used without the lease.
+			var out []string
+			ts.post(t, "/api/periph/v1/gpio/out", map[string]bool{"WEB2": true}, &out, 409)
+			var r []i2cTxResult
+			ts.post(t, "/api/periph/v1/i2c/tx", []i2cTx{{Bus: "WEBI2C"}}, &r, 409)
+			// Nor with another name of the bus.
+			ts.post(t, "/api/periph/v1/i2c/tx", []i2cTx{{Bus: ""}}, &r, 409)
+			ts.post(t, "/api/periph/v1/i2c/tx", []i2cTx{{Bus: "99"}}, &r, 409)
+			ts.post(t, "/api/periph/v1/i2c/scan", &i2cScanIn{}, &i2cScanOut{}, 409)
+			ts.post(t, "/api/periph/v1/sequence", []sequenceStep{{Op: "i2c/tx", I2C: &i2cTx{}}}, &sequenceOut{}, 409)
+			ts.post(t, "/api/periph/v1/lease/acquire", &leaseIn{Pins: []string{"WEB2"}}, &leaseOut{}, 409)
+			if f.pins[1].L != gpio.Low {
+				t.Fatal("pin was modified")
+			}
+			// But can be with it.
+			ts.postWith(t, http.Header{leaseHeader: {l.ID}}, "/api/periph/v1/gpio/out", map[string]bool{"WEB2": true}, &out, 200)
+			if f.pins[1].L != gpio.High {
+				t.Fatal("pin was not modified")
+			}
+		},
+		"/api/periph/v1/lease/list": func(t *testing.T) {
+			var l leaseOut
+			ts.post(t, "/api/periph/v1/lease/acquire", &leaseIn{Holder: "test", SPI: []string{"WEBSPI"}}, &l, 200)
+			defer ts.post(t, "/api/periph/v1/lease/release", &leaseRef{ID: l.ID}, nil, 200)
+			ts.post(t, "/api/periph/v1/spi/tx", &spiTx{}, &spiTxResult{}, 409)
+			var out []leaseInfo
+			ts.post(t, "/api/periph/v1/lease/list", map[string]string{}, &out, 200)
+			if len(out) != 1 || out[0].Holder != "test" || !reflect.DeepEqual(out[0].SPI, []string{"WEBSPI"}) {
+				t.Fatal(out)
+			}
+		},
+		"/api/periph/v1/lease/release": func(t *testing.T) {
+			ts.post(t, "/api/periph/v1/lease/release", nil, nil, 400)
+			var l leaseOut
+			ts.post(t, "/api/periph/v1/lease/acquire", &leaseIn{Pins: []string{"WEB2"}}, &l, 200)
+			ts.post(t, "/api/periph/v1/lease/release", &leaseRef{ID: l.ID}, &l, 200)
+			if l.Err != "" {
+				t.Fatal(l)
+			}
+			ts.post(t, "/api/periph/v1/lease/release", &leaseRef{ID: l.ID}, &l, 200)
+			if l.Err == "" {
+				t.Fatal("expected error")
+			}
+		},
+		"/api/periph/v1/lease/renew": func(t *testing.T) {
+			ts.post(t, "/api/periph/v1/lease/renew", nil, nil, 400)
+			var l leaseOut
+			ts.post(t, "/api/periph/v1/lease/acquire", &leaseIn{Pins: []string{"WEB2"}, TTL: "10s"}, &l, 200)
+			defer ts.post(t, "/api/periph/v1/lease/release", &leaseRef{ID: l.ID}, nil, 200)
+			var r leaseOut
+			ts.post(t, "/api/periph/v1/lease/renew", &leaseRef{ID: l.ID, TTL: "1m"}, &r, 200)
+			if r.Err != "" || !r.Expires.After(l.Expires) {
+				t.Fatal(l, r)
+			}
+		},
+		"/api/periph/v1/onewire/ds18b20/read": func(t *testing.T) {
+			ts.post(t, "/api/periph/v1/onewire/ds18b20/read", nil, nil, 400)
+			// Search, read the configuration, convert then read the temperature.
+			f.setOneWire(
+				onewiretest.IO{W: []byte{0xF0}},
+				onewiretest.IO{W: []byte{0x55, 0x28, 0xAC, 0x41, 0x0E, 0x07, 0x00, 0x00, 0x74, 0xBE}, R: []byte{0xE0, 0x01, 0x00, 0x00, 0x3F, 0xFF, 0x10, 0x10, 0x3F}},
+				onewiretest.IO{W: []byte{0xCC, 0x44}, Pull: onewire.StrongPullup},
+				onewiretest.IO{W: []byte{0x55, 0x28, 0xAC, 0x41, 0x0E, 0x07, 0x00, 0x00, 0x74, 0xBE}, R: []byte{0xE0, 0x01, 0x00, 0x00, 0x3F, 0xFF, 0x10, 0x10, 0x3F}},
+			)
+			var out []ds18b20Reading
+			ts.post(t, "/api/periph/v1/onewire/ds18b20/read", &ds18b20ReadIn{Bus: "WEB1W"}, &out, 200)
+			if len(out) != 1 || out[0].Err != "" || out[0].Addr != "0x740000070e41ac28" || out[0].Celsius != 30 {
+				t.Fatal(out)
+			}
+		},
+		"/api/periph/v1/onewire/list": func(t *testing.T) {
+			f.setOneWire(onewiretest.IO{W: []byte{0xF0}})
+			var out []onewireRef
+			ts.post(t, "/api/periph/v1/onewire/list", map[string]string{}, &out, 200)
+			want := onewireDevice{Addr: "0x740000070e41ac28", Family: 0x28, FamilyName: "DS18B20", Serial: "0000070e41ac"}
+			if len(out) != 1 || out[0].Err != "" || !reflect.DeepEqual(out[0].Devices, []onewireDevice{want}) {
+				t.Fatal(out)
+			}
+		},
+		"/api/periph/v1/sequence": func(t *testing.T) {
+			in := []sequenceStep{
+				{Op: "gpio/out", Pin: "WEB1", Level: true},
+				{Op: "sleep", Duration: "1ms"},
+				{Op: "gpio/read", Pin: "WEB1"},
+				{Op: "spi/tx", SPI: &spiTx{Port: "WEBSPI", Mode: 4}},
+				{Op: "gpio/out", Pin: "WEB1", Level: false},
+			}
+			var out sequenceOut
+			ts.post(t, "/api/periph/v1/sequence", in, &out, 200)
+			if len(out.Steps) != 4 || out.Steps[2].Level != 1 || out.Steps[1].Duration < time.Millisecond || out.Err == "" {
+				t.Fatal(out)
+			}
+			if f.pins[0].L != gpio.High {
+				t.Fatal("the sequence didn't stop at the first error")
+			}
+			// A call using a pin of a running sequence waits for it to complete.
+			req := newRequest(t, "POST", ts.url+"/api/periph/v1/gpio/out", `{"WEB_ALIAS":false}`)
+			req.Header.Set("Content-Type", "application/json")
+			req.AddCookie(&http.Cookie{Name: "XSRF-TOKEN", Value: ts.token})
+			done := make(chan error)
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				resp, err := http.DefaultClient.Do(req)
+				if err == nil {
+					_ = resp.Body.Close()
+				}
+				done <- err
+			}()
+			ts.post(t, "/api/periph/v1/sequence", []sequenceStep{{Op: "gpio/out", Pin: "WEB1", Level: true}, {Op: "sleep", Duration: "200ms"}, {Op: "gpio/read", Pin: "WEB1"}}, &out, 200)
+			if err := <-done; err != nil {
+				t.Fatal(err)
+			}
+			if len(out.Steps) != 3 || out.Steps[2].Level != 1 {
+				t.Fatal("the sequence was interleaved", out)
+			}
+			if f.pins[0].L != gpio.Low {
+				t.Fatal("gpio/out didn't run after the sequence")
+			}
+			ts.post(t, "/api/periph/v1/sequence", []sequenceStep{{Op: "spi/tx", SPI: &spiTx{Port: "NOPE"}}}, &out, 400)
+			ts.post(t, "/api/periph/v1/sequence", []sequenceStep{{Op: "nope"}}, &out, 200)
+			if out.Err != `step 0: invalid op "nope"` {
+				t.Fatal(out)
+			}
+		},
+		"/api/periph/v1/spi/list": func(t *testing.T) {
+			var out []spiRef
+			ts.post(t, "/api/periph/v1/spi/list", map[string]string{}, &out, 200)
+			if !contains(out, spiRef{Name: "WEBSPI", Aliases: []string{}, Number: 99, CLK: "INVALID", MOSI: "INVALID", MISO: "INVALID", CS: "INVALID"}) {
+				t.Fatal(out)
+			}
+		},
+		"/api/periph/v1/spi/tx": func(t *testing.T) {
+			ts.post(t, "/api/periph/v1/spi/tx", nil, nil, 400)
+			f.setSPI(conntest.IO{W: []byte{1, 2}, R: []byte{3, 4}})
+			var out spiTxResult
+			ts.post(t, "/api/periph/v1/spi/tx", &spiTx{Port: "WEBSPI", W: byteList{1, 2}}, &out, 200)
+			if out.Err != "" || !bytes.Equal(out.R, []byte{3, 4}) {
+				t.Fatal(out)
+			}
+			ts.post(t, "/api/periph/v1/spi/tx", &spiTx{Port: "WEBSPI", Mode: 4}, &out, 200)
+			if out.Err != "invalid mode" {
+				t.Fatal(out)
+			}
+			ts.post(t, "/api/periph/v1/spi/tx", &spiTx{Port: "NOPE"}, &out, 400)
+		},
+		"/api/periph/v1/server/state": func(t *testing.T) {
+			var out serverStateOut
+			ts.post(t, "/api/periph/v1/server/state", map[string]string{}, &out, 200)
+			if out.Hostname != "localhost" {
+				t.Fatal(out)
+			}
+		},
+	}
+	for _, h := range ts.s.apis.getAPIs() {
+		fn := tests[h.path]
+		if fn == nil {
+			t.Errorf("%s is not tested", h.path)
+			continue
+		}
+		t.Run(strings.TrimPrefix(h.path, "/api/periph/v1/"), fn)
+	}
+}
+
+// TestWebXSRF verifies that the JSON APIs require a valid XSRF token, either
+// as a cookie or as a header.
+func TestWebXSRF(t *testing.T) {
+	ts := newTestServer(t, &webOpts{})
+	const path = "/api/periph/v1/server/state"
+	for _, c := range []struct {
+		cookie, header string
+		status         int
+		body           string
+	}{
+		{"", "", 400, "Missing XSRF-TOKEN cookie or X-XSRF-Token header\n"},
+		{"x" + ts.token, "", 400, "Invalid XSRF-TOKEN cookie\n"},
+		{"", "x" + ts.token, 400, "Invalid X-XSRF-Token header\n"},
+		// The header has precedence.
+		{ts.token, "x" + ts.token, 400, "Invalid X-XSRF-Token header\n"},
+		{ts.token, "", 200, ""},
+		{"", ts.token, 200, ""},
+	} {
+		req := newRequest(t, "POST", ts.url+path, "{}")
+		req.Header.Set("Content-Type", "application/json")
+		if c.cookie != "" {
+			req.AddCookie(&http.Cookie{Name: "XSRF-TOKEN", Value: c.cookie})
+		}
+		if c.header != "" {
+			req.Header.Set(xsrfHeader, c.header)
+		}
+		status, body := do(t, req)
+		if status != c.status || (c.body != "" && body != c.body) {
+			t.Fatalf("cookie=%q header=%q: %d %q", c.cookie, c.header, status, body)
+		}
+	}
+	// The token is only returned on POST.
+	if status, _ := do(t, newRequest(t, "GET", ts.url+"/raw/periph/v1/xsrf_token", "")); status != 405 {
+		t.Fatal(status)
+	}
+}
+
+// TestWebRequests verifies how the JSON APIs reject malformed requests.
+func TestWebRequests(t *testing.T) {
+	f := registerFakes(t)
+	ts := newTestServer(t, &webOpts{})
+	for _, c := range []struct {
+		method, path, contentType, body string
+		status                          int
+		want                            string
+	}{
+		{"GET", "/api/periph/v1/gpio/list", "", "", 405, "Only POST is allowed\n"},
+		{"POST", "/api/periph/v1/gpio/list?a=b", "application/json", "{}", 400, "Do not use query argment\n"},
+		{"POST", "/api/periph/v1/gpio/list", "text/plain", "{}", 400, "Content-Type must be application/json\n"},
+		// The handler must not be called when the input is invalid.
+		{"POST", "/api/periph/v1/gpio/list", "application/json", `{"a":"b"}`, 400, "Unexpected data\n"},
+		{"POST", "/api/periph/v1/gpio/list", "application/json", `[]`, 400, "Malformed user data: json: cannot unmarshal array into Go value of type map[string]string\n"},
+		{"POST", "/api/periph/v1/gpio/out", "application/json", `{"WEB1":1}`, 400, "Malformed user data: json: cannot unmarshal number into Go struct field .WEB1 of type bool\n"},
+		{"POST", "/api/periph/v1/gpio/in", "application/json", `[{"Name":"WEB1","Nope":1}]`, 400, "Malformed user data: json: unknown field \"Nope\"\n"},
+		{"POST", "/api/periph/v1/nope", "application/json", "", 404, "Not Found\n"},
+	} {
+		req := newRequest(t, c.method, ts.url+c.path, c.body)
+		if c.contentType != "" {
+			req.Header.Set("Content-Type", c.contentType)
+		}
+		req.AddCookie(&http.Cookie{Name: "XSRF-TOKEN", Value: ts.token})
+		if status, body := do(t, req); status != c.status || body != c.want {
+			t.Fatalf("%s %s %s: %d %q", c.method, c.path, c.body, status, body)
+		}
+	}
+	if f.pins[0].L != gpio.Low || f.pins[0].P != gpio.PullNoChange {
+		t.Fatal("pin was modified")
+	}
+}
+
+// TestWebEvents verifies that the edges are streamed.
+func TestWebEvents(t *testing.T) {
+	f := registerFakes(t)
+	ts := newTestServer(t, &webOpts{})
+	req := newRequest(t, "GET", ts.url+"/raw/periph/v1/gpio/events", "")
+	req.AddCookie(&http.Cookie{Name: "XSRF-TOKEN", Value: ts.token})
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); resp.StatusCode != 200 || ct != "text/event-stream" {
+		t.Fatal(resp.StatusCode, ct)
+	}
+	events := make(chan string, 16)
+	go func() {
+		defer close(events)
+		s := bufio.NewScanner(resp.Body)
+		var e string
+		for s.Scan() {
+			if s.Text() == "" {
+				events <- e
+				e = ""
+				continue
+			}
+			e += s.Text() + "\n"
+		}
+	}()
+	next := func() string {
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("no event")
+			return ""
+		}
+	}
+	if e := next(); e != "event: watch\ndata: []\n" {
+		t.Fatalf("%q", e)
+	}
+	var out []string
+	ts.post(t, "/api/periph/v1/gpio/in", []pinIn{{Name: "WEB1", Edge: "both"}}, &out, 200)
+	if e := next(); e != "event: watch\ndata: [\"WEB1\"]\n" {
+		t.Fatalf("%q", e)
+	}
+	f.pins[0].EdgesChan <- gpio.High
+	e := next()
+	var edge gpioEdge
+	if !strings.HasPrefix(e, "event: edge\ndata: ") || json.Unmarshal([]byte(strings.TrimPrefix(e, "event: edge\ndata: ")), &edge) != nil {
+		t.Fatalf("%q", e)
+	}
+	if edge.Name != "WEB1" || !edge.Level {
+		t.Fatal(edge)
+	}
+}
+
+// TestWebHandlers verifies the handlers that are not JSON APIs.
+func TestWebHandlers(t *testing.T) {
+	ts := newTestServer(t, &webOpts{})
+	for _, c := range []struct {
+		method, path string
+		status       int
+		contentType  string
+	}{
+		{"GET", "/", 200, "text/html"},
+		{"POST", "/", 405, "text/plain; charset=utf-8"},
+		{"GET", "/nope", 404, "text/plain; charset=utf-8"},
+		{"GET", "/favicon.ico", 200, "image/png"},
+		{"GET", "/metrics", 200, "text/plain; version=0.0.4; charset=utf-8"},
+		{"POST", "/metrics", 405, "text/plain; charset=utf-8"},
+		{"GET", "/api/periph/v1/openapi.json", 200, "application/json"},
+		{"POST", "/raw/periph/v1/xsrf_token", 200, "text/plain"},
+	} {
+		resp, err := http.DefaultClient.Do(newRequest(t, c.method, ts.url+c.path, ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if ct := resp.Header.Get("Content-Type"); resp.StatusCode != c.status || ct != c.contentType {
+			t.Fatalf("%s %s: %d %q", c.method, c.path, resp.StatusCode, ct)
+		}
+	}
+
+	// The OpenAPI document describes every JSON API.
+	_, body := do(t, newRequest(t, "GET", ts.url+"/api/periph/v1/openapi.json", ""))
+	var doc struct{ Paths map[string]interface{} }
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range ts.s.apis.getAPIs() {
+		if doc.Paths[h.path] == nil {
+			t.Errorf("%s is missing from the OpenAPI document", h.path)
+		}
+	}
+
+	// The metrics count the requests by route.
+	_, body = do(t, newRequest(t, "GET", ts.url+"/metrics", ""))
+	if !strings.Contains(body, `periph_http_requests_total{path="/favicon.ico",code="200"} 1`) {
+		t.Fatal(body)
+	}
+
+	// The history is disabled by default.
+	req := newRequest(t, "GET", ts.url+"/api/periph/v1/history", "")
+	req.AddCookie(&http.Cookie{Name: "XSRF-TOKEN", Value: ts.token})
+	if status, body := do(t, req); status != 200 || !strings.Contains(body, "history is disabled") {
+		t.Fatal(status, body)
+	}
+}
+
+// TestMetricsHandlerWriter verifies that the streaming handlers can still
+// flush and hijack the connection behind metricsHandler.
+func TestMetricsHandlerWriter(t *testing.T) {
+	var m httpMetrics
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.Flusher); !ok {
+			t.Error("not a http.Flusher")
+		}
+		h, ok := w.(http.Hijacker)
+		if !ok {
+			t.Error("not a http.Hijacker")
+			return
+		}
+		conn, _, err := h.Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, _ = io.WriteString(conn, "HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n")
+		_ = conn.Close()
+	})
+	ts := httptest.NewServer(metricsHandler(&m, mux, loggingHandler(mux)))
+	defer ts.Close()
+	if status, _ := do(t, newRequest(t, "GET", ts.URL, "")); status != 200 {
+		t.Fatal(status)
+	}
+}
+
+// testServer is a periph-web server listening on an ephemeral port.
+type testServer struct {
+	s     *webServer
+	url   string
+	token string
+}
+
+func newTestServer(t *testing.T, opts *webOpts) *testServer {
+	s, err := newWebServer("127.0.0.1:0", &driverreg.State{}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := s.Close(); err != nil {
+			t.Error(err)
+		}
+		if err := s.apis.restorePins(); err != nil {
+			t.Error(err)
+		}
+	})
+	ts := &testServer{s: s, url: "http://" + s.server.Addr}
+	status, body := do(t, newRequest(t, "POST", ts.url+"/raw/periph/v1/xsrf_token", ""))
+	if status != 200 {
+		t.Fatal(status, body)
+	}
+	ts.token = body
+	return ts
+}
+
+// post calls the JSON API at path and decodes the response into out, if not
+// nil. It fails the test if the status is not status.
+func (ts *testServer) post(t *testing.T, path string, in, out interface{}, status int) {
+	t.Helper()
+	ts.postWith(t, nil, path, in, out, status)
+}
+
+// postWith is post with additional headers.
+func (ts *testServer) postWith(t *testing.T, hdr http.Header, path string, in, out interface{}, status int) {
+	t.Helper()
+	raw, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := newRequest(t, "POST", ts.url+path, string(raw))
+	for k, v := range hdr {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "XSRF-TOKEN", Value: ts.token})
+	s, body := do(t, req)
+	if s != status {
+		t.Fatalf("%s: got %d, expected %d: %s", path, s, status, body)
+	}
+	if out != nil {
+		if err = json.Unmarshal([]byte(body), out); err != nil {
+			t.Fatalf("%s: %v: %s", path, err, body)
+		}
+	}
+}
+
+func newRequest(t *testing.T, method, url, body string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+// do sends the request and returns the status and the body.
+func do(t *testing.T, req *http.Request) (int, string) {
+	t.Helper()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(raw)
+}
+
+func contains[T any](l []T, v T) bool {
+	for i := range l {
+		if reflect.DeepEqual(l[i], v) {
+			return true
+		}
+	}
+	return false
+}
+
+// fakes are fake GPIOs, I²C and SPI buses and 1-Wire bus registered in the
+// periph registries.
+//
+// The buses play back the operations set by the test. Each time a bus is
+// opened, it plays back the operations from the start.
+type fakes struct {
+	pins [2]*gpiotest.Pin
+
+	mu      sync.Mutex
+	i2c     []i2ctest.IO
+	spi     []conntest.IO
+	onewire []onewiretest.IO
+}
+
+// registerFakes registers the pins "WEB1" (with the alias "WEB_ALIAS") and
+// "WEB2", the I²C bus "WEBI2C", the SPI port "WEBSPI" and the 1-Wire bus
+// "WEB1W" with a DS18B20. They are unregistered at the end of the test.
+func registerFakes(t *testing.T) *fakes {
+	f := &fakes{
+		pins: [2]*gpiotest.Pin{
+			{N: "WEB1", Num: 2001, Fn: "In/Low", EdgesChan: make(chan gpio.Level, 1)},
+			{N: "WEB2", Num: 2002, Fn: "Out/Low"},
+		},
+	}
+	for _, p := range f.pins {
+		if err := gpioreg.Register(p); err != nil {
+			t.Fatal(err)
+		}
+		name := p.N
+		t.Cleanup(func() { _ = gpioreg.Unregister(name) })
+	}
+	if err := gpioreg.RegisterAlias("WEB_ALIAS", "WEB1"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = gpioreg.Unregister("WEB_ALIAS") })
+	// The bus numbers are unlikely to exist, so i2c/scan can't use the
+	// kernel's quick write.
+	if err := i2creg.Register("WEBI2C", nil, 99, func() (i2c.BusCloser, error) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return &i2ctest.Playback{Ops: f.i2c, DontPanic: true, SCLPin: gpio.INVALID, SDAPin: gpio.INVALID}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = i2creg.Unregister("WEBI2C") })
+	if err := spireg.Register("WEBSPI", nil, 99, func() (spi.PortCloser, error) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return &spitest.Playback{
+			Playback: conntest.Playback{Ops: f.spi, DontPanic: true},
+			CLKPin:   gpio.INVALID,
+			MOSIPin:  gpio.INVALID,
+			MISOPin:  gpio.INVALID,
+			CSPin:    gpio.INVALID,
+		}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = spireg.Unregister("WEBSPI") })
+	if err := onewirereg.Register("WEB1W", nil, 99, func() (onewire.BusCloser, error) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return &onewiretest.Playback{Ops: f.onewire, Devices: []onewire.Address{0x740000070e41ac28}, DontPanic: true, QPin: gpio.INVALID}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = onewirereg.Unregister("WEB1W") })
+	return f
+}
+
+func (f *fakes) setI2C(ops ...i2ctest.IO) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.i2c = ops
+}
+
+func (f *fakes) setSPI(ops ...conntest.IO) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.spi = ops
+}
+
+func (f *fakes) setOneWire(ops ...onewiretest.IO) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onewire = ops
+}